@@ -0,0 +1,149 @@
+package wfc
+
+import (
+	"fmt"
+	"image"
+)
+
+/**
+ * BigTile Type. Tile spanning several slots of the output, split into one part per slot.
+ * Parts are only allowed next to the parts they touch in the image, so the whole tile is always placed at once.
+ * Constraints refer to the whole tile by its name, and apply to every part along the corresponding edge.
+ * Like other tiles, big tiles are rotated and mirrored unless NoRotation is set.
+ */
+type BigTile struct {
+	Name   string      // Name used to identify the tile
+	Width  int         // Width of the tile, in slots
+	Height int         // Height of the tile, in slots
+	Weight float64     // Weight of each part. Default to 1, when unset (0)
+	Image  image.Image // Image of the whole tile, Width by Height tiles
+}
+
+// Part of a big tile
+type bigTilePart struct {
+	tile BigTile     // The whole tile
+	slot image.Point // Slot of the part within the tile
+}
+
+// Check whether the part touches another part of its tile in the given direction (see tileDirections), before transforming the tile
+func (part bigTilePart) linked(direction int) bool {
+	return part.slot.Add(tileDirections[direction]).In(image.Rect(0, 0, part.tile.Width, part.tile.Height))
+}
+
+// Name of the part of a big tile in slot (x, y) of the tile
+func partName(name string, x, y int) string {
+	return fmt.Sprintf("%s:%d,%d", name, x, y)
+}
+
+/**
+ * Copy the data, replacing every big tile by its parts and every constraint on a big tile by
+ * constraints on its parts, and adding the constraints holding the parts together
+ * returns: the data, and the parts by name
+ */
+func (data SimpleTiledData) withBigTiles() (SimpleTiledData, map[string]bigTilePart) {
+	parts := make(map[string]bigTilePart)
+	if len(data.BigTiles) == 0 {
+		return data, parts
+	}
+	bigTiles := make(map[string]BigTile)
+	for _, tile := range data.BigTiles {
+		bigTiles[tile.Name] = tile
+	}
+
+	/**
+	 * Names of the tiles touching the neighbor in the given direction (see tileDirections),
+	 * once the tile is transformed according to num
+	 */
+	edge := func(name string, num, direction int) []string {
+		tile, ok := bigTiles[name]
+		if !ok {
+			return []string{name}
+		}
+		// Direction of the neighbor before transforming the tile
+		for d := range tileDirections {
+			if num >= 0 && num < transformCount && transformDirection(d, num) == direction {
+				direction = d
+				break
+			}
+		}
+		result := make([]string, 0)
+		for y := 0; y < tile.Height; y++ {
+			for x := 0; x < tile.Width; x++ {
+				if !(bigTilePart{tile, image.Pt(x, y)}).linked(direction) {
+					result = append(result, partName(name, x, y))
+				}
+			}
+		}
+		return result
+	}
+
+	result := data
+	result.BigTiles = nil
+	result.Tiles = append([]Tile{}, data.Tiles...)
+	result.Neighbors = make([]Neighbor, 0, len(data.Neighbors))
+	result.VerticalNeighbors = make([]VerticalNeighbor, 0, len(data.VerticalNeighbors))
+	result.DiagonalNeighbors = make([]DiagonalNeighbor, 0, len(data.DiagonalNeighbors))
+
+	width, height := data.tileBounds()
+	for _, tile := range data.BigTiles {
+		for y := 0; y < tile.Height; y++ {
+			for x := 0; x < tile.Width; x++ {
+				// Parts have no symmetry, so that each orientation of the tile has its own orientation of the parts
+				part := Tile{Name: partName(tile.Name, x, y), Symmetry: "F", Weight: tile.Weight}
+				// Unique tilesets need an image per orientation, which for "F" is the transform leading to it
+				part.Variants = make([]image.Image, 1)
+				transforms := []int{0}
+				if data.Unique {
+					part.Variants = make([]image.Image, transformCount)
+					transforms = data.transforms()
+				}
+				for _, k := range transforms {
+					part.Variants[k] = patternImage(cellPattern(tile.Image, x*width, y*height, width, height, k), width, height)
+				}
+				result.Tiles = append(result.Tiles, part)
+				parts[part.Name] = bigTilePart{tile, image.Pt(x, y)}
+
+				if x+1 < tile.Width {
+					result.Neighbors = append(result.Neighbors, Neighbor{Left: part.Name, Right: partName(tile.Name, x+1, y)})
+				}
+				if y+1 < tile.Height {
+					result.VerticalNeighbors = append(result.VerticalNeighbors, VerticalNeighbor{Top: part.Name, Bottom: partName(tile.Name, x, y+1)})
+				}
+				// Diagonal directions are only constrained when there are diagonal constraints
+				if len(data.DiagonalNeighbors) > 0 && y+1 < tile.Height {
+					if x+1 < tile.Width {
+						result.DiagonalNeighbors = append(result.DiagonalNeighbors, DiagonalNeighbor{Top: part.Name, Bottom: partName(tile.Name, x+1, y+1)})
+					}
+					if x > 0 {
+						result.DiagonalNeighbors = append(result.DiagonalNeighbors, DiagonalNeighbor{Top: part.Name, Bottom: partName(tile.Name, x-1, y+1), Leftward: true})
+					}
+				}
+			}
+		}
+	}
+
+	for _, neighbor := range data.Neighbors {
+		for _, left := range edge(neighbor.Left, neighbor.LeftNum, 2) {
+			for _, right := range edge(neighbor.Right, neighbor.RightNum, 0) {
+				result.Neighbors = append(result.Neighbors, Neighbor{Left: left, LeftNum: neighbor.LeftNum, Right: right, RightNum: neighbor.RightNum})
+			}
+		}
+	}
+	for _, neighbor := range data.VerticalNeighbors {
+		for _, top := range edge(neighbor.Top, neighbor.TopNum, 1) {
+			for _, bottom := range edge(neighbor.Bottom, neighbor.BottomNum, 3) {
+				result.VerticalNeighbors = append(result.VerticalNeighbors, VerticalNeighbor{Top: top, TopNum: neighbor.TopNum, Bottom: bottom, BottomNum: neighbor.BottomNum})
+			}
+		}
+	}
+	for _, neighbor := range data.DiagonalNeighbors {
+		direction := neighbor.direction()
+		for _, top := range edge(neighbor.Top, neighbor.TopNum, direction) {
+			for _, bottom := range edge(neighbor.Bottom, neighbor.BottomNum, oppositeDirection(direction)) {
+				result.DiagonalNeighbors = append(result.DiagonalNeighbors, DiagonalNeighbor{Top: top, TopNum: neighbor.TopNum, Bottom: bottom, BottomNum: neighbor.BottomNum, Leftward: neighbor.Leftward})
+			}
+		}
+	}
+
+	return result, parts
+}
@@ -0,0 +1,97 @@
+package wfc
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestBigTiles(t *testing.T) {
+	grass := color.RGBA{0, 160, 0, 255}
+	keep := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	quarters := []color.RGBA{{200, 0, 0, 255}, {0, 0, 200, 255}, {200, 200, 0, 255}, {0, 200, 200, 255}}
+	for i, c := range quarters {
+		draw.Draw(keep, image.Rect(i%2*2, i/2*2, i%2*2+2, i/2*2+2), &image.Uniform{c}, image.Point{}, draw.Src)
+	}
+	data := SimpleTiledData{
+		TileSize: 2,
+		Tiles: []Tile{
+			{Name: "grass", Symmetry: "X", Variants: []image.Image{solidImage(grass, 2)}},
+		},
+		BigTiles: []BigTile{
+			{Name: "keep", Width: 2, Height: 2, Weight: 4, Image: keep},
+		},
+		Neighbors: []Neighbor{
+			{Left: "grass", Right: "grass"},
+			{Left: "keep", Right: "grass"},
+			{Left: "grass", Right: "keep"},
+		},
+		VerticalNeighbors: []VerticalNeighbor{
+			{Top: "grass", Bottom: "grass"},
+			{Top: "keep", Bottom: "grass"},
+			{Top: "grass", Bottom: "keep"},
+		},
+	}
+
+	diagonalNeighbors := []DiagonalNeighbor{
+		{Top: "grass", Bottom: "grass"},
+		{Top: "keep", Bottom: "grass"},
+		{Top: "grass", Bottom: "keep"},
+		{Top: "grass", Bottom: "grass", Leftward: true},
+		{Top: "keep", Bottom: "grass", Leftward: true},
+		{Top: "grass", Bottom: "keep", Leftward: true},
+	}
+	for _, settings := range []struct{ noRotation, diagonal bool }{{true, false}, {false, false}, {true, true}, {false, true}} {
+		noRotation := settings.noRotation
+		data.NoRotation = noRotation
+		data.DiagonalNeighbors = nil
+		if settings.diagonal {
+			data.DiagonalNeighbors = diagonalNeighbors
+		}
+		model, err := NewSimpleTiledModelChecked(data, 9, 9, false)
+		if err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+		model.SetSeed(42)
+		outputImg, success := model.Generate()
+		if !success {
+			t.Log("Failed to generate image on the first try.")
+			t.FailNow()
+		}
+
+		// Every quarter of the keep appears equally often, so keeps are never split
+		counts := make(map[color.Color]int)
+		for x := 0; x < 9; x++ {
+			for y := 0; y < 9; y++ {
+				counts[outputImg.At(x*2, y*2)]++
+			}
+		}
+		for _, c := range quarters {
+			if counts[c] == 0 || counts[c] != counts[quarters[0]] {
+				t.Logf("Expected whole keeps, got %v.", counts)
+				t.FailNow()
+			}
+		}
+
+		// Without rotation, keeps appear exactly as drawn
+		if noRotation {
+			for x := 0; x < 9; x++ {
+				for y := 0; y < 9; y++ {
+					if outputImg.At(x*2, y*2) == quarters[0] && (outputImg.At(x*2+2, y*2) != quarters[1] || outputImg.At(x*2, y*2+2) != quarters[2] || outputImg.At(x*2+2, y*2+2) != quarters[3]) {
+						t.Logf("Keep at (%d, %d) is not drawn as in its image.", x, y)
+						t.FailNow()
+					}
+				}
+			}
+		}
+	}
+
+	data.Neighbors[1].Left = "keap"
+	data.BigTiles[0].Width = 3
+	if err := data.Validate(); err == nil {
+		t.Log("Expected an unknown tile and an image smaller than the big tile to be reported.")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,200 @@
+package wfc
+
+import (
+	"math"
+	"math/bits"
+)
+
+/**
+ * Deterministic mode. Floating-point results may differ between platforms and versions of Go (e.g. math.Log, or
+ * multiplications fused with additions on arm64), which can change the slot chosen among slots of almost equal
+ * entropy. When BaseModel.Deterministic is set, observation only uses integer arithmetic on fixed-point weights,
+ * and seeds use the SplitMix64 generator, so that a seed gives the same output on every platform.
+ */
+
+const (
+	weightBits  = 24 // Precision of fixed-point weights, the largest weight being 1 << weightBits
+	entropyBits = 16 // Precision of fixed-point logarithms and entropies
+	noiseBits   = 16 // Random bits breaking ties between slots of equal score
+)
+
+// SplitMix64 generator (see https://prng.di.unimi.it/splitmix64.c), the random source of seeds in deterministic mode
+type splitMix64 uint64
+
+func (state *splitMix64) Uint64() uint64 {
+	*state += 0x9e3779b97f4a7c15
+	z := uint64(*state)
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Weights scaled to fixed-point integers. Positive weights stay positive, so that they can still be chosen.
+func fixedWeights(stationary []float64) []uint64 {
+	max := 0.0
+	for _, weight := range stationary {
+		if weight > max && !math.IsInf(weight, 1) {
+			max = weight
+		}
+	}
+	weights := make([]uint64, len(stationary))
+	for t, weight := range stationary {
+		if !(weight > 0) || max == 0 {
+			continue
+		}
+		// Each operation is rounded on its own, identically on every platform
+		scaled := float64(weight / max)
+		weights[t] = uint64(math.Round(math.Min(scaled, 1) * (1 << weightBits)))
+		if weights[t] == 0 {
+			weights[t] = 1
+		}
+	}
+	return weights
+}
+
+// Base 2 logarithm of x > 0, in fixed point, rounded down
+func fixedLog2(x uint64) uint64 {
+	n := bits.Len64(x) - 1
+	result := uint64(n) << entropyBits
+
+	// Mantissa in [1, 2), with 31 fractional bits. Squaring it doubles its logarithm, so each square past 2
+	// gives the next bit of the fractional part.
+	var y uint64
+	if n > 31 {
+		y = x >> uint(n-31)
+	} else {
+		y = x << uint(31-n)
+	}
+	for bit := uint64(1) << (entropyBits - 1); bit > 0; bit >>= 1 {
+		y = y * y >> 31
+		if y >= 1<<32 {
+			y >>= 1
+			result |= bit
+		}
+	}
+	return result
+}
+
+// Entropy of the weights of the allowed patterns, in fixed point, given their sum
+func fixedEntropy(weights []uint64, allowed []bool, sum uint64) uint64 {
+	// entropy = log2(sum) - sum(w * log2(w)) / sum, computed on 128 bits
+	hi, lo := bits.Mul64(sum, fixedLog2(sum))
+	for t, ok := range allowed {
+		if !ok || weights[t] == 0 {
+			continue
+		}
+		termHi, termLo := bits.Mul64(weights[t], fixedLog2(weights[t]))
+		var borrow uint64
+		lo, borrow = bits.Sub64(lo, termLo, 0)
+		hi, borrow = bits.Sub64(hi, termHi, borrow)
+		if borrow != 0 {
+			// Rounding of the logarithms made the entropy slightly negative
+			return 0
+		}
+	}
+	entropy, _ := bits.Div64(hi, lo, sum)
+	return entropy
+}
+
+/**
+ * Same as Observe, with integer arithmetic only
+ * returns: finished (bool)
+ */
+func (baseModel *BaseModel) observeDeterministic(specificModel AppliedAlgorithm) bool {
+	if baseModel.rng64 == nil {
+		// Deterministic was set during a generation, after seeding math/rand
+		baseModel.seedRng(baseModel.Seed)
+	}
+	if baseModel.weights == nil {
+		// Deterministic was set during a generation, after clearing
+		baseModel.weights = fixedWeights(baseModel.Stationary)
+	}
+	weights := baseModel.weights
+	min := uint64(math.MaxUint64)
+	argminx := -1
+	argminy := -1
+	undecidable := false
+
+	// Find the point with minimum score for the heuristic (adding random low bits to break ties)
+	for x := 0; x < baseModel.Fmx; x++ {
+		for y := 0; y < baseModel.Fmy; y++ {
+			if specificModel.OnBoundary(x, y) {
+				continue
+			}
+
+			sum := uint64(0)
+			amount := 0
+			for t := 0; t < baseModel.T; t++ {
+				if baseModel.Wave[x][y][t] {
+					sum += weights[t]
+					amount++
+				}
+			}
+
+			if amount == 0 {
+				baseModel.GenerationSuccessful = false
+				return true // finished, unsuccessful
+			}
+
+			if sum == 0 {
+				// Only patterns with no weight remain, which are never chosen by observation
+				if amount > 1 {
+					undecidable = true
+				}
+				continue
+			}
+
+			if amount == 1 {
+				continue
+			}
+
+			var score uint64
+			switch baseModel.Heuristic {
+			case MRVHeuristic:
+				score = uint64(amount)<<noiseBits | baseModel.rng64()>>(64-noiseBits)
+			case ScanlineHeuristic:
+				score = uint64(x + y*baseModel.Fmx)
+			default:
+				score = fixedEntropy(weights, baseModel.Wave[x][y], sum)<<noiseBits | baseModel.rng64()>>(64-noiseBits)
+			}
+
+			if score < min {
+				min = score
+				argminx = x
+				argminy = y
+			}
+		}
+	}
+
+	if argminx == -1 && argminy == -1 {
+		baseModel.GenerationSuccessful = !undecidable
+		return true // finished, successful unless some slots could not be decided
+	}
+
+	// Choose a pattern with probability proportional to its weight
+	sum := uint64(0)
+	for t := 0; t < baseModel.T; t++ {
+		if baseModel.Wave[argminx][argminy][t] {
+			sum += weights[t]
+		}
+	}
+	r, _ := bits.Mul64(baseModel.rng64(), sum)
+	chosen := -1
+	for t := 0; t < baseModel.T && chosen == -1; t++ {
+		if !baseModel.Wave[argminx][argminy][t] {
+			continue
+		}
+		if r < weights[t] {
+			chosen = t
+		}
+		r -= weights[t]
+	}
+
+	for t := 0; t < baseModel.T; t++ {
+		baseModel.Wave[argminx][argminy][t] = (t == chosen)
+	}
+
+	baseModel.Changes[argminx][argminy] = true
+
+	return false // Not finished yet
+}
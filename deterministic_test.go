@@ -0,0 +1,92 @@
+package wfc
+
+import (
+	"github.com/shawnridgeway/wfc/internal/testutils"
+	"reflect"
+	"testing"
+)
+
+func TestFixedLog2(t *testing.T) {
+	for x, expected := range map[uint64]uint64{1: 0, 2: 1 << 16, 3: 103872, 1 << 40: 40 << 16, 1<<63 + 1: 63 << 16} {
+		if log := fixedLog2(x); log != expected {
+			t.Logf("Expected log2(%d) to be %d, got %d.", x, expected, log)
+			t.FailNow()
+		}
+	}
+
+	// Uniform weights have the entropy log2(n)
+	weights := []uint64{5, 5, 5, 5, 7}
+	if entropy := fixedEntropy(weights, []bool{true, true, true, true, false}, 20); entropy != 2<<16 {
+		t.Logf("Expected an entropy of 2, got %v.", float64(entropy)/(1<<16))
+		t.FailNow()
+	}
+}
+
+func deterministicTest(t *testing.T, model Model, seed int64, snapshotFilename string) {
+	model.SetSeed(seed)
+	outputImg, success := model.Generate()
+	if !success {
+		t.Log("Failed to generate image on the first try.")
+		t.FailNow()
+	}
+
+	// Save output
+	// err := testutils.SaveImage("internal/snapshots/"+snapshotFilename, outputImg)
+	// if err != nil {
+	// 	panic(err)
+	// }
+
+	// Test that files match
+	snapshotImg, err := testutils.LoadImage("internal/snapshots/" + snapshotFilename)
+	if err != nil {
+		panic(err)
+	}
+	if !testutils.CompareImages(outputImg, snapshotImg) {
+		t.Log("Output image is not the same as the snapshot image.")
+		t.FailNow()
+	}
+}
+
+func TestDeterministicOverlapping(t *testing.T) {
+	inputImg, err := testutils.LoadImage("internal/input/flowers.png")
+	if err != nil {
+		panic(err)
+	}
+	model, err := NewOverlapping(inputImg, 48, 48, WithPeriodic(true), WithSymmetry(2), WithGround(true), WithDeterministic(true))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	deterministicTest(t, model, 42, "flowers_deterministic.png")
+}
+
+func TestDeterministicSimpleTiled(t *testing.T) {
+	model, err := NewSimpleTiled(initiateData("castle_data.json"), 20, 20, WithDeterministic(true))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	// Seed 42 runs into a contradiction
+	deterministicTest(t, model, 43, "castle_deterministic.png")
+}
+
+func TestDeterministicWeightsPerGeneration(t *testing.T) {
+	model, err := NewSimpleTiled(initiateData("castle_data.json"), 10, 10, WithDeterministic(true))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	model.Clear()
+	if !reflect.DeepEqual(model.weights, fixedWeights(model.Stationary)) {
+		t.Log("Expected the weights to be computed when clearing.")
+		t.FailNow()
+	}
+
+	// Weights changed between generations apply to the next one
+	model.Stationary[0] *= 2
+	model.Clear()
+	if !reflect.DeepEqual(model.weights, fixedWeights(model.Stationary)) {
+		t.Log("Expected the weights to be computed again for a new generation.")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,65 @@
+package wfc
+
+import (
+	"image/color"
+	"math"
+)
+
+/**
+ * InferNeighbors
+ * Generate neighbor constraints by comparing the edge pixels of every orientation of every tile, as drawn
+ * by NewSimpleTiledModel. Two orientations can be neighbors when the right column of pixels of the left one
+ * matches the left column of pixels of the right one, and likewise for the rows of vertical neighbors.
+ * The existing constraints of data are ignored.
+ * @param {SimpleTiledData} data The tiles to compare
+ * @param {float64} tolerance Largest difference allowed between two facing pixels, in any channel, from 0 (exact match) to 1
+ * @return SimpleTiledData A copy of data with the inferred constraints
+ * @return error A *ValidationError if a tile or the tolerance is invalid
+ */
+func InferNeighbors(data SimpleTiledData, tolerance float64) (SimpleTiledData, error) {
+	data.Neighbors = nil
+	data.VerticalNeighbors = nil
+	v := &validator{subject: "tileset"}
+	data.validate(v)
+	if tolerance < 0 || tolerance > 1 || math.IsNaN(tolerance) {
+		v.add("tolerance must be between 0 and 1, got %v", tolerance)
+	}
+	if err := v.result(); err != nil {
+		return SimpleTiledData{}, err
+	}
+
+	orientations := newTileOrientations(data.Tiles, data.transforms())
+	patterns := orientationPatterns(data, orientations)
+	width, height := data.tileBounds()
+	limit := uint32(tolerance * 0xffff)
+
+	data.Neighbors, data.VerticalNeighbors = orientations.neighbors(data.Tiles, func(l, r int) bool {
+		for y := 0; y < height; y++ {
+			if !colorsMatch(patterns[l][width-1+y*width], patterns[r][y*width], limit) {
+				return false
+			}
+		}
+		return true
+	}, func(u, d int) bool {
+		for x := 0; x < width; x++ {
+			if !colorsMatch(patterns[u][x+(height-1)*width], patterns[d][x], limit) {
+				return false
+			}
+		}
+		return true
+	})
+	return data, nil
+}
+
+// Check whether no channel of two colors differs by more than limit
+func colorsMatch(a, b color.Color, limit uint32) bool {
+	r1, g1, b1, a1 := a.RGBA()
+	r2, g2, b2, a2 := b.RGBA()
+	diff := func(x, y uint32) uint32 {
+		if x > y {
+			return x - y
+		}
+		return y - x
+	}
+	return diff(r1, r2) <= limit && diff(g1, g2) <= limit && diff(b1, b2) <= limit && diff(a1, a2) <= limit
+}
@@ -0,0 +1,85 @@
+package wfc
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestInferNeighbors(t *testing.T) {
+	green := color.RGBA{0, 160, 0, 255}
+	blue := color.RGBA{0, 0, 160, 255}
+	shore := image.NewRGBA(image.Rect(0, 0, 3, 3))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			if x == 2 || y == 0 {
+				shore.Set(x, y, green)
+			} else {
+				shore.Set(x, y, blue)
+			}
+		}
+	}
+	data := SimpleTiledData{
+		TileSize: 3,
+		Tiles: []Tile{
+			{Name: "land", Symmetry: "X", Variants: []image.Image{solidImage(green, 3)}},
+			{Name: "sea", Symmetry: "X", Variants: []image.Image{solidImage(blue, 3)}},
+			{Name: "shore", Symmetry: "L", Variants: []image.Image{shore}},
+		},
+	}
+
+	inferred, err := InferNeighbors(data, 0)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	model := NewSimpleTiledModel(inferred, 12, 12, false)
+	model.SetSeed(42)
+	output, success := model.Generate()
+	if !success {
+		t.Log("Failed to generate image on the first try.")
+		t.FailNow()
+	}
+
+	// Pixels on both sides of every tile boundary are identical
+	bounds := output.Bounds()
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			if x%3 == 2 && x+1 < bounds.Dx() && output.At(x, y) != output.At(x+1, y) {
+				t.Logf("Pixels at (%d, %d) and (%d, %d) do not match.", x, y, x+1, y)
+				t.FailNow()
+			}
+			if y%3 == 2 && y+1 < bounds.Dy() && output.At(x, y) != output.At(x, y+1) {
+				t.Logf("Pixels at (%d, %d) and (%d, %d) do not match.", x, y, x, y+1)
+				t.FailNow()
+			}
+		}
+	}
+
+	// A slightly different color only matches within the tolerance
+	data.Tiles = append(data.Tiles, Tile{Name: "shallows", Symmetry: "X", Variants: []image.Image{solidImage(color.RGBA{0, 0, 165, 255}, 3)}})
+	count := func(tolerance float64) int {
+		inferred, err := InferNeighbors(data, tolerance)
+		if err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+		result := 0
+		for _, neighbor := range inferred.Neighbors {
+			if (neighbor.Left == "sea") != (neighbor.Right == "sea") && (neighbor.Left == "shallows" || neighbor.Right == "shallows") {
+				result++
+			}
+		}
+		return result
+	}
+	if count(0) != 0 || count(0.05) == 0 {
+		t.Log("Expected the tolerance to decide whether similar colors match.")
+		t.FailNow()
+	}
+
+	if _, err := InferNeighbors(data, 2); err == nil {
+		t.Log("Expected an invalid tolerance to be rejected.")
+		t.FailNow()
+	}
+}
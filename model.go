@@ -4,7 +4,6 @@ import (
     // "fmt"
     "image"
     "math"
-    "math/rand"
     "time"
 )
 
@@ -24,6 +23,39 @@ type AppliedAlgorithm interface {
     Clear()
 }
 
+/**
+ * Model Type. Operations shared by every model, to use them interchangeably.
+ */
+type Model interface {
+    Iterator
+    Generator
+    GenerateWithSeed(seed int64) (image.Image, bool)
+    Render() image.Image
+    SetSeed(seed int64)
+    SetSource(source Source)
+    RandomState() ([]byte, error)
+    SetRandomState(state []byte) error
+    IsGenerationSuccessful() bool
+    Clear()
+    SetSize(width, height int) error
+    PatternGrid() [][]int
+    Possibilities(x, y int) []int
+    Entropy(x, y int) float64
+    IsCollapsed(x, y int) bool
+    CollapsedCount() int
+}
+
+/**
+ * Heuristic Type. Rule choosing which slot to observe next.
+ */
+type Heuristic int
+
+const (
+    EntropyHeuristic  Heuristic = iota // Slot with the lowest entropy of the weights of its patterns, as in the original algorithm
+    MRVHeuristic                       // Slot with the fewest patterns left (minimum remaining values), ignoring weights
+    ScanlineHeuristic                  // First undecided slot, row by row from the top left
+)
+
 type BaseModel struct {
     InitiliazedField     bool           // Generation Initialized
     RngSet               bool           // Random number generator set by user
@@ -35,6 +67,14 @@ type BaseModel struct {
     Periodic             bool           // Output is periodic (ie tessellates)
     Fmx, Fmy             int            // Width and height of output
     Rng                  func() float64 // Random number generator supplied at generation time
+    Seed                 int64          // Seed the default random number generator was last seeded with, by SetSeed, a generation or a restored state. Unused while a source is set (see SetSource)
+    fixedSeed            int64          // Seed set by the user, used by every generation when RngSet
+    source               Source         // Random source set by the user, used instead of seeds when not nil
+    draws                uint64         // Count of numbers drawn from the seed of the current generation
+    Heuristic            Heuristic      // Rule choosing the slot to observe. Default to EntropyHeuristic
+    Deterministic        bool           // Observe with integer arithmetic only, for identical outputs on every platform (see observeDeterministic)
+    rng64                func() uint64  // Random number generator of the deterministic mode, drawing from the same stream as Rng
+    weights              []uint64       // Fixed-point weights of the deterministic mode, computed once per generation
 }
 
 /**
@@ -42,12 +82,17 @@ type BaseModel struct {
  * returns: finished (bool)
  */
 func (baseModel *BaseModel) Observe(specificModel AppliedAlgorithm) bool {
-    min := 1000.0
+    if baseModel.Deterministic {
+        return baseModel.observeDeterministic(specificModel)
+    }
+
+    min := math.Inf(1)
     argminx := -1
     argminy := -1
+    undecidable := false
     distribution := make([]float64, baseModel.T)
 
-    // Find the point with minimum entropy (adding a little noise for randomness)
+    // Find the point with minimum score for the heuristic (adding a little noise for randomness)
     for x := 0; x < baseModel.Fmx; x++ {
         for y := 0; y < baseModel.Fmy; y++ {
             if specificModel.OnBoundary(x, y) {
@@ -55,37 +100,55 @@ func (baseModel *BaseModel) Observe(specificModel AppliedAlgorithm) bool {
             }
 
             sum := 0.0
+            amount := 0
 
             for t := 0; t < baseModel.T; t++ {
                 if baseModel.Wave[x][y][t] {
                     distribution[t] = baseModel.Stationary[t]
+                    amount++
                 } else {
                     distribution[t] = 0.0
                 }
                 sum += distribution[t]
             }
 
-            if sum == 0.0 {
+            if amount == 0 {
                 baseModel.GenerationSuccessful = false
                 return true // finished, unsuccessful
             }
 
-            for t := 0; t < baseModel.T; t++ {
-                distribution[t] /= sum
+            if sum == 0.0 {
+                // Only patterns with no weight remain, which are never chosen by observation
+                if amount > 1 {
+                    undecidable = true
+                }
+                continue
             }
 
-            entropy := 0.0
+            var score float64
+            switch baseModel.Heuristic {
+            case MRVHeuristic:
+                score = float64(amount) + 0.000001*baseModel.Rng()
+            case ScanlineHeuristic:
+                score = float64(x + y*baseModel.Fmx)
+            default:
+                for t := 0; t < baseModel.T; t++ {
+                    distribution[t] /= sum
+                }
+
+                entropy := 0.0
 
-            for i := 0; i < len(distribution); i++ {
-                if distribution[i] > 0.0 {
-                    entropy += -distribution[i] * math.Log(distribution[i])
+                for i := 0; i < len(distribution); i++ {
+                    if distribution[i] > 0.0 {
+                        entropy += -distribution[i] * math.Log(distribution[i])
+                    }
                 }
-            }
 
-            noise := 0.000001 * baseModel.Rng()
+                score = entropy + 0.000001*baseModel.Rng()
+            }
 
-            if entropy > 0 && entropy+noise < min {
-                min = entropy + noise
+            if amount > 1 && score < min {
+                min = score
                 argminx = x
                 argminy = y
             }
@@ -93,8 +156,8 @@ func (baseModel *BaseModel) Observe(specificModel AppliedAlgorithm) bool {
     }
 
     if argminx == -1 && argminy == -1 {
-        baseModel.GenerationSuccessful = true
-        return true // finished, successful
+        baseModel.GenerationSuccessful = !undecidable
+        return true // finished, successful unless some slots could not be decided
     }
 
     for t := 0; t < baseModel.T; t++ {
@@ -171,14 +234,156 @@ func (baseModel *BaseModel) IsGenerationSuccessful() bool {
     return baseModel.GenerationSuccessful
 }
 
+/**
+ * Retrieve the patterns still allowed in slot (x, y)
+ * returns: ids of the patterns, in increasing order
+ */
+func (baseModel *BaseModel) Possibilities(x, y int) []int {
+    result := make([]int, 0)
+    for t := 0; t < baseModel.T; t++ {
+        if baseModel.Wave[x][y][t] {
+            result = append(result, t)
+        }
+    }
+    return result
+}
+
+/**
+ * Compute the entropy of slot (x, y), from the weights of the patterns still allowed there (see Observe)
+ * returns: entropy, 0 if the slot is collapsed or only patterns without weight are left
+ */
+func (baseModel *BaseModel) Entropy(x, y int) float64 {
+    sum := 0.0
+    for t := 0; t < baseModel.T; t++ {
+        if baseModel.Wave[x][y][t] {
+            sum += baseModel.Stationary[t]
+        }
+    }
+    if sum == 0.0 {
+        return 0.0
+    }
+
+    entropy := 0.0
+    for t := 0; t < baseModel.T; t++ {
+        if baseModel.Wave[x][y][t] && baseModel.Stationary[t] > 0.0 {
+            p := baseModel.Stationary[t] / sum
+            entropy += -p * math.Log(p)
+        }
+    }
+    return entropy
+}
+
+/**
+ * Check whether exactly one pattern is left in slot (x, y)
+ */
+func (baseModel *BaseModel) IsCollapsed(x, y int) bool {
+    amount := 0
+    for t := 0; t < baseModel.T && amount < 2; t++ {
+        if baseModel.Wave[x][y][t] {
+            amount++
+        }
+    }
+    return amount == 1
+}
+
+/**
+ * Count the slots where exactly one pattern is left
+ */
+func (baseModel *BaseModel) CollapsedCount() int {
+    count := 0
+    for x := 0; x < baseModel.Fmx; x++ {
+        for y := 0; y < baseModel.Fmy; y++ {
+            if baseModel.IsCollapsed(x, y) {
+                count++
+            }
+        }
+    }
+    return count
+}
+
+/**
+ * Retrieve the pattern chosen for every slot of the output, as a [x][y] grid
+ * returns: id of the only pattern left in each slot, or -1 where the slot is undecided or contradicted
+ */
+func (baseModel *BaseModel) PatternGrid() [][]int {
+    grid := make([][]int, baseModel.Fmx)
+    for x := 0; x < baseModel.Fmx; x++ {
+        grid[x] = make([]int, baseModel.Fmy)
+        for y := 0; y < baseModel.Fmy; y++ {
+            grid[x][y] = -1
+            for t := 0; t < baseModel.T; t++ {
+                if !baseModel.Wave[x][y][t] {
+                    continue
+                }
+                if grid[x][y] != -1 {
+                    grid[x][y] = -1
+                    break
+                }
+                grid[x][y] = t
+            }
+        }
+    }
+    return grid
+}
+
 /**
  * Set the seed for the random number generator. Useful for a stable testing environment.
+ * Every following generation starts from this seed, so that they all give the same output.
  */
 func (baseModel *BaseModel) SetSeed(seed int64) {
-    baseModel.Rng = rand.New(rand.NewSource(seed)).Float64
+    baseModel.fixedSeed = seed
+    baseModel.source = nil
+    baseModel.seedRng(seed)
     baseModel.RngSet = true
 }
 
+/**
+ * Execute a complete new generation from the given seed, without changing the seed of other generations.
+ */
+func (baseModel *BaseModel) GenerateWithSeed(specificModel AppliedAlgorithm, seed int64) {
+    rngSet, fixedSeed, source := baseModel.RngSet, baseModel.fixedSeed, baseModel.source
+    baseModel.SetSeed(seed)
+    baseModel.Generate(specificModel)
+    baseModel.RngSet, baseModel.fixedSeed = rngSet, fixedSeed
+    if source != nil {
+        // Unlike seeds, a source is not restored by clearing, so draw from it again
+        baseModel.SetSource(source)
+    }
+}
+
+/**
+ * Allocate the wave (to all true) and changes (to all false) fields for an output of Fmx by Fmy
+ */
+func (baseModel *BaseModel) allocate() {
+    baseModel.Wave = make([][][]bool, baseModel.Fmx)
+    baseModel.Changes = make([][]bool, baseModel.Fmx)
+    for x := 0; x < baseModel.Fmx; x++ {
+        baseModel.Wave[x] = make([][]bool, baseModel.Fmy)
+        baseModel.Changes[x] = make([]bool, baseModel.Fmy)
+        for y := 0; y < baseModel.Fmy; y++ {
+            baseModel.Wave[x][y] = make([]bool, baseModel.T)
+            for t := 0; t < baseModel.T; t++ {
+                baseModel.Wave[x][y][t] = true
+            }
+        }
+    }
+}
+
+/**
+ * Change the size of the output, reallocating the state only if the size is different.
+ * The next generation starts from a cleared state.
+ */
+func (baseModel *BaseModel) resize(width, height int) {
+    if width == baseModel.Fmx && height == baseModel.Fmy {
+        return
+    }
+    baseModel.Fmx = width
+    baseModel.Fmy = height
+    baseModel.allocate()
+    baseModel.InitiliazedField = false
+    baseModel.GenerationSuccessful = false
+}
+
 /**
  * Clear the internal state to start a new generation
  */
@@ -191,8 +396,15 @@ func (baseModel *BaseModel) ClearBase(specificModel AppliedAlgorithm) {
             baseModel.Changes[x][y] = false
         }
     }
-    if !baseModel.RngSet {
-        baseModel.Rng = rand.New(rand.NewSource(time.Now().UnixNano())).Float64
+    // A source set by the user carries on from its current state
+    if baseModel.source == nil && baseModel.RngSet {
+        baseModel.seedRng(baseModel.fixedSeed)
+    } else if baseModel.source == nil {
+        baseModel.seedRng(time.Now().UnixNano())
+    }
+    baseModel.weights = nil
+    if baseModel.Deterministic {
+        baseModel.weights = fixedWeights(baseModel.Stationary)
     }
     baseModel.InitiliazedField = true
     baseModel.GenerationSuccessful = false
@@ -0,0 +1,271 @@
+package wfc
+
+import (
+	"image"
+)
+
+// Settings shared by every model, collected from options
+type modelOptions struct {
+	periodic      bool
+	seed          int64
+	seedSet       bool
+	heuristic     Heuristic
+	deterministic bool
+}
+
+// Settings of an overlapping model, starting from the defaults
+type overlappingOptions struct {
+	modelOptions
+	periodicInput bool
+	symmetry      int
+	symmetrySet   bool // Transforms given by WithSymmetry, rather than WithTransforms
+	transforms    Transforms
+	ground        bool
+	n             int
+}
+
+// Settings of a simple tiled model, starting from the defaults
+type simpleTiledOptions struct {
+	modelOptions
+	subset    string
+	subsetSet bool
+}
+
+/**
+ * OverlappingSetting Type. Anything given to NewOverlapping: an Option or an OverlappingOption.
+ */
+type OverlappingSetting interface {
+	applyOverlapping(o *overlappingOptions)
+}
+
+/**
+ * SimpleTiledSetting Type. Anything given to NewSimpleTiled: an Option or a SimpleTiledOption.
+ */
+type SimpleTiledSetting interface {
+	applySimpleTiled(o *simpleTiledOptions)
+}
+
+/**
+ * Option Type. Setting of any model.
+ */
+type Option func(*modelOptions)
+
+func (opt Option) applyOverlapping(o *overlappingOptions) {
+	opt(&o.modelOptions)
+}
+
+func (opt Option) applySimpleTiled(o *simpleTiledOptions) {
+	opt(&o.modelOptions)
+}
+
+/**
+ * OverlappingOption Type. Setting that only applies to overlapping models, so that giving it to another model
+ * does not compile.
+ */
+type OverlappingOption func(*overlappingOptions)
+
+func (opt OverlappingOption) applyOverlapping(o *overlappingOptions) {
+	opt(o)
+}
+
+/**
+ * SimpleTiledOption Type. Setting that only applies to simple tiled models, so that giving it to another model
+ * does not compile.
+ */
+type SimpleTiledOption func(*simpleTiledOptions)
+
+func (opt SimpleTiledOption) applySimpleTiled(o *simpleTiledOptions) {
+	opt(o)
+}
+
+/**
+ * Make the output periodic, so that it tiles seamlessly. Default to false.
+ */
+func WithPeriodic(periodic bool) Option {
+	return func(o *modelOptions) {
+		o.periodic = periodic
+	}
+}
+
+/**
+ * Use a stable seed for the random number generator (see SetSeed). Default to a seed based on the current time.
+ */
+func WithSeed(seed int64) Option {
+	return func(o *modelOptions) {
+		o.seed, o.seedSet = seed, true
+	}
+}
+
+/**
+ * Choose the slots to observe with the given rule. Default to EntropyHeuristic.
+ */
+func WithHeuristic(heuristic Heuristic) Option {
+	return func(o *modelOptions) {
+		o.heuristic = heuristic
+	}
+}
+
+/**
+ * Generate identical outputs from a seed on every platform (see BaseModel.Deterministic). Default to false.
+ */
+func WithDeterministic(deterministic bool) Option {
+	return func(o *modelOptions) {
+		o.deterministic = deterministic
+	}
+}
+
+/**
+ * Consider the sample of an overlapping model as periodic. Default to true.
+ */
+func WithPeriodicInput(periodicInput bool) OverlappingOption {
+	return func(o *overlappingOptions) {
+		o.periodicInput = periodicInput
+	}
+}
+
+/**
+ * Include the first `symmetry` transforms of the patterns of an overlapping model, from 1 to 8 (see SymmetryTransforms).
+ * Default to 8.
+ */
+func WithSymmetry(symmetry int) OverlappingOption {
+	return func(o *overlappingOptions) {
+		o.symmetry, o.symmetrySet = symmetry, true
+	}
+}
+
+/**
+ * Include the given transforms of the patterns of an overlapping model. Default to AllTransforms.
+ */
+func WithTransforms(transforms Transforms) OverlappingOption {
+	return func(o *overlappingOptions) {
+		o.transforms, o.symmetrySet = transforms, false
+	}
+}
+
+/**
+ * Use the bottom left pattern of the sample of an overlapping model as the bottom of the output. Default to false.
+ */
+func WithGround(ground bool) OverlappingOption {
+	return func(o *overlappingOptions) {
+		o.ground = ground
+	}
+}
+
+/**
+ * Use patterns of N by N pixels in an overlapping model. Default to 3.
+ */
+func WithN(n int) OverlappingOption {
+	return func(o *overlappingOptions) {
+		o.n = n
+	}
+}
+
+/**
+ * Only use the tiles of the named subset of a simple tiled model (see SimpleTiledData.Subsets).
+ */
+func WithSubset(subset string) SimpleTiledOption {
+	return func(o *simpleTiledOptions) {
+		o.subset, o.subsetSet = subset, true
+	}
+}
+
+// Check the settings shared by every model
+func (o modelOptions) validate(v *validator) {
+	if o.heuristic < EntropyHeuristic || o.heuristic > ScanlineHeuristic {
+		v.add("unknown heuristic %d", o.heuristic)
+	}
+}
+
+// Apply the settings shared by every model
+func (o modelOptions) apply(baseModel *BaseModel) {
+	baseModel.Heuristic = o.heuristic
+	baseModel.Deterministic = o.deterministic
+	if o.seedSet {
+		baseModel.SetSeed(o.seed)
+	}
+}
+
+// Transforms of the patterns, given by WithSymmetry or WithTransforms
+func (o overlappingOptions) patternTransforms() Transforms {
+	if !o.symmetrySet {
+		return o.transforms
+	}
+	if o.symmetry < 1 || o.symmetry > transformCount {
+		// Reported by validate, only the identity is left to check the other settings
+		return Identity
+	}
+	return SymmetryTransforms(o.symmetry)
+}
+
+// Check the settings of an overlapping model
+func (o overlappingOptions) validate(v *validator) {
+	o.modelOptions.validate(v)
+	if o.symmetrySet && (o.symmetry < 1 || o.symmetry > transformCount) {
+		v.add("symmetry must be between 1 and %d, got %d", transformCount, o.symmetry)
+	}
+}
+
+/**
+ * NewOverlapping
+ * Create an overlapping model, configured with options.
+ * @param {image.Image} img The source image
+ * @param {int} width The width of the generated image
+ * @param {int} height The height of the generated image
+ * @param {...OverlappingSetting} opts Settings of the model: WithN, WithPeriodicInput, WithPeriodic, WithSymmetry or
+ * WithTransforms, WithGround, WithSeed, WithHeuristic and WithDeterministic
+ * @return *OverlappingModel A pointer to a new copy of the model
+ * @return error A *ValidationError listing every problem with the input and options, or nil
+ */
+func NewOverlapping(img image.Image, width, height int, opts ...OverlappingSetting) (*OverlappingModel, error) {
+	o := overlappingOptions{periodicInput: true, transforms: AllTransforms, n: 3}
+	for _, opt := range opts {
+		opt.applyOverlapping(&o)
+	}
+
+	v := &validator{subject: "overlapping model"}
+	o.validate(v)
+	transforms := o.patternTransforms()
+	validateOverlapping(v, img, o.n, width, height, o.periodicInput, o.periodic, transforms)
+	if err := v.result(); err != nil {
+		return nil, err
+	}
+	model := newOverlappingModel(img, o.n, width, height, o.periodicInput, o.periodic, transforms, o.ground)
+	o.apply(model.BaseModel)
+	return model, nil
+}
+
+/**
+ * NewSimpleTiled
+ * Create a simple tiled model, configured with options.
+ * @param {SimpleTiledData} data The tiles and constraints
+ * @param {int} width The width of the generated image, in tiles
+ * @param {int} height The height of the generated image, in tiles
+ * @param {...SimpleTiledSetting} opts Settings of the model: WithSubset, WithPeriodic, WithSeed, WithHeuristic and
+ * WithDeterministic
+ * @return *SimpleTiledModel A pointer to a new copy of the model
+ * @return error A *ValidationError listing every problem with the input and options, or nil
+ */
+func NewSimpleTiled(data SimpleTiledData, width, height int, opts ...SimpleTiledSetting) (*SimpleTiledModel, error) {
+	var o simpleTiledOptions
+	for _, opt := range opts {
+		opt.applySimpleTiled(&o)
+	}
+
+	v := &validator{subject: "tileset"}
+	o.validate(v)
+	if o.subsetSet {
+		if subset, err := data.Subset(o.subset); err != nil {
+			v.add("unknown subset %q", o.subset)
+		} else {
+			data = subset
+		}
+	}
+	data.validate(v)
+	validateOutputSize(v, width, height)
+	if err := v.result(); err != nil {
+		return nil, err
+	}
+	model := NewSimpleTiledModel(data, width, height, o.periodic)
+	o.apply(model.BaseModel)
+	return model, nil
+}
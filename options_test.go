@@ -0,0 +1,129 @@
+package wfc
+
+import (
+	"github.com/shawnridgeway/wfc/internal/testutils"
+	"strings"
+	"testing"
+)
+
+var (
+	_ Model = (*OverlappingModel)(nil)
+	_ Model = (*SimpleTiledModel)(nil)
+)
+
+func TestOptionsMatchPositionalConstructors(t *testing.T) {
+	inputImg, err := testutils.LoadImage("internal/input/flowers.png")
+	if err != nil {
+		panic(err)
+	}
+	overlapping, err := NewOverlapping(inputImg, 48, 48, WithPeriodic(true), WithSymmetry(2), WithGround(true), WithSeed(42))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	simpleTiled, err := NewSimpleTiled(initiateData("castle_data.json"), 20, 20, WithSeed(42))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	for snapshotFilename, model := range map[string]Model{"flowers.png": overlapping, "castle.png": simpleTiled} {
+		outputImg, success := model.Generate()
+		if !success {
+			t.Log("Failed to generate image on the first try.")
+			t.FailNow()
+		}
+		snapshotImg, err := testutils.LoadImage("internal/snapshots/" + snapshotFilename)
+		if err != nil {
+			panic(err)
+		}
+		if !testutils.CompareImages(outputImg, snapshotImg) {
+			t.Logf("Output image is not the same as the snapshot image %s.", snapshotFilename)
+			t.FailNow()
+		}
+	}
+}
+
+func TestOptionsHeuristics(t *testing.T) {
+	data := initiateData("castle_data.json")
+	for _, heuristic := range []Heuristic{MRVHeuristic, ScanlineHeuristic} {
+		model, err := NewSimpleTiled(data, 10, 10, WithHeuristic(heuristic), WithSeed(42))
+		if err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+		if _, success := model.Generate(); !success {
+			t.Logf("Failed to generate image with heuristic %d.", heuristic)
+			t.FailNow()
+		}
+	}
+
+	// Scanline observes the slots in order, so the first row is decided first
+	model, _ := NewSimpleTiled(data, 10, 10, WithHeuristic(ScanlineHeuristic), WithSeed(42))
+	model.Iterate(10)
+	for x := 0; x < 10; x++ {
+		if !model.IsCollapsed(x, 0) {
+			t.Logf("Slot (%d, 0) is undecided after observing the first row.", x)
+			t.FailNow()
+		}
+	}
+}
+
+func TestOptionsValidation(t *testing.T) {
+	inputImg, err := testutils.LoadImage("internal/input/flowers.png")
+	if err != nil {
+		panic(err)
+	}
+
+	// Options of other models are rejected by the compiler, e.g. WithSubset here
+	_, err = NewOverlapping(inputImg, 48, 48, WithSymmetry(9), WithHeuristic(Heuristic(7)))
+	for _, expected := range []string{"symmetry must be between 1 and 8, got 9", "unknown heuristic 7"} {
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Logf("Expected %q to be reported, got: %v", expected, err)
+			t.FailNow()
+		}
+	}
+	if _, err := NewOverlapping(inputImg, 48, 48, WithSymmetry(9), WithTransforms(Rotations)); err != nil {
+		t.Logf("Transforms given last should override the symmetry, got: %v", err)
+		t.FailNow()
+	}
+
+	data := initiateData("castle_data.json")
+	_, err = NewSimpleTiled(data, 20, 20, WithSubset("missing"), WithPeriodic(true))
+	if err == nil || !strings.Contains(err.Error(), `unknown subset "missing"`) {
+		t.Logf("Expected the unknown subset to be reported, got: %v", err)
+		t.FailNow()
+	}
+}
+
+func TestSeedsAreReproducible(t *testing.T) {
+	data := initiateData("castle_data.json")
+	model, err := NewSimpleTiled(data, 10, 10)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	// Without a fixed seed, the seed actually used can replay the generation
+	first, _ := model.Generate()
+	replayed, _ := model.GenerateWithSeed(model.Seed)
+	if !testutils.CompareImages(first, replayed) {
+		t.Log("Replaying the recorded seed gave another output.")
+		t.FailNow()
+	}
+
+	// A fixed seed gives the same output however many generations preceded
+	model.SetSeed(42)
+	expected, _ := model.Generate()
+	model.Generate()
+	model.GenerateWithSeed(7)
+	if model.Seed != 7 {
+		t.Logf("Expected the seed of the last generation to be 7, got %d.", model.Seed)
+		t.FailNow()
+	}
+	output, _ := model.Generate()
+	if model.Seed != 42 || !testutils.CompareImages(expected, output) {
+		t.Log("Generations from the same fixed seed differ.")
+		t.FailNow()
+	}
+}
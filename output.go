@@ -18,7 +18,7 @@ func (gi GeneratedImage) Bounds() image.Rectangle {
 	if len(gi.data) < 1 {
 		return image.Rect(0, 0, 0, 0)
 	}
-	return image.Rect(0, 0, len(gi.data[0]), len(gi.data))
+	return image.Rect(0, 0, len(gi.data), len(gi.data[0]))
 }
 
 func (gi GeneratedImage) At(x, y int) color.Color {
@@ -38,6 +38,26 @@ type Pattern []int
  * @return *OverlappingModel A pointer to a new copy of the model
  */
 func NewOverlappingModel(img image.Image, n, width, height int, periodicInput, periodicOutput bool, symmetry int, ground bool) *OverlappingModel {
+	return newOverlappingModel(img, n, width, height, periodicInput, periodicOutput, SymmetryTransforms(symmetry), ground)
+}
+
+/**
+ * NewOverlappingModelWithTransforms
+ * Same as NewOverlappingModel, but with an explicit set of transforms instead of the symmetry shorthand.
+ * @param {Transforms} transforms Reflections and rotations of the extracted patterns to include, must contain Identity
+ * @return *OverlappingModel A pointer to a new copy of the model
+ * @return error A *ValidationError listing every problem with the input, or nil
+ */
+func NewOverlappingModelWithTransforms(img image.Image, n, width, height int, periodicInput, periodicOutput bool, transforms Transforms, ground bool) (*OverlappingModel, error) {
+	v := &validator{subject: "overlapping model"}
+	validateOverlapping(v, img, n, width, height, periodicInput, periodicOutput, transforms)
+	if err := v.result(); err != nil {
+		return nil, err
+	}
+	return newOverlappingModel(img, n, width, height, periodicInput, periodicOutput, transforms, ground), nil
+}
+
+func newOverlappingModel(img image.Image, n, width, height int, periodicInput, periodicOutput bool, transforms Transforms, ground bool) *OverlappingModel {
 
 	// Initialize model
 	model := &OverlappingModel{BaseModel: &BaseModel{}}
@@ -156,7 +176,10 @@ func NewOverlappingModel(img image.Image, n, width, height int, periodicInput, p
 			ps[5] = reflect(ps[4])
 			ps[6] = rotate(ps[4])
 			ps[7] = reflect(ps[6])
-			for k := 0; k < symmetry; k++ {
+			for k := 0; k < transformCount; k++ {
+				if !transforms.Has(k) {
+					continue
+				}
 				ind := indexFromPattern(ps[k])
 				if _, ok := weights[ind]; ok {
 					weights[ind]++
@@ -184,19 +207,7 @@ func NewOverlappingModel(img image.Image, n, width, height int, periodicInput, p
 	}
 
 	// Initialize wave (to all true) and changes (to all false) fields
-	model.Wave = make([][][]bool, model.Fmx)
-	model.Changes = make([][]bool, model.Fmx)
-	for x := 0; x < model.Fmx; x++ {
-		model.Wave[x] = make([][]bool, model.Fmy)
-		model.Changes[x] = make([]bool, model.Fmy)
-		for y := 0; y < model.Fmy; y++ {
-			model.Wave[x][y] = make([]bool, model.T)
-			model.Changes[x][y] = false
-			for t := 0; t < model.T; t++ {
-				model.Wave[x][y][t] = true
-			}
-		}
-	}
+	model.allocate()
 
 	// Check that the spaces n distance away have no conflicts
 	agrees := func(p1, p2 Pattern, dx, dy int) bool {
@@ -258,6 +269,29 @@ func NewOverlappingModel(img image.Image, n, width, height int, periodicInput, p
 	return model
 }
 
+/**
+ * SetSize
+ * Change the size of the generated image, keeping the patterns and propagator.
+ * The state of the generation is only reallocated if the size is different, and is cleared in that case.
+ * @param {int} width The width of the generated image
+ * @param {int} height The height of the generated image
+ * @return error A *ValidationError if the size is invalid, in which case the model is unchanged
+ */
+func (model *OverlappingModel) SetSize(width, height int) error {
+	v := &validator{subject: "size"}
+	validateOutputSize(v, width, height)
+	if !model.Periodic && width > 0 && height > 0 && (width < model.N || height < model.N) {
+		v.add("output size %dx%d is smaller than the pattern size %d", width, height, model.N)
+	}
+	if err := v.result(); err != nil {
+		return err
+	}
+	model.resize(width, height)
+	model.Fmxmn = model.Fmx - model.N
+	model.Fmymn = model.Fmy - model.N
+	return nil
+}
+
 /**
  * OnBoundary
  */
@@ -459,3 +493,12 @@ func (model *OverlappingModel) Generate() (image.Image, bool) {
 	model.BaseModel.Generate(model)
 	return model.Render(), model.IsGenerationSuccessful()
 }
+
+/**
+ * Retrieve the RGBA data of a new generation from the given seed, without changing the seed of other generations
+ * returns: Image, successful
+ */
+func (model *OverlappingModel) GenerateWithSeed(seed int64) (image.Image, bool) {
+	model.BaseModel.GenerateWithSeed(model, seed)
+	return model.Render(), model.IsGenerationSuccessful()
+}
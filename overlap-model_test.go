@@ -67,3 +67,183 @@ func TestOverlappingGenerationCompletes(t *testing.T) {
 func TestOverlappingIterationIncomplete(t *testing.T) {
 	overlappingTest(t, "flowers.png", "flowers_incomplete.png", 5)
 }
+
+func TestOverlappingTransforms(t *testing.T) {
+	inputImg, err := testutils.LoadImage("internal/input/flowers.png")
+	if err != nil {
+		panic(err)
+	}
+
+	if SymmetryTransforms(2) != Identity|FlipX || SymmetryTransforms(8) != AllTransforms {
+		t.Log("Symmetry shorthand does not match the transform set.")
+		t.FailNow()
+	}
+
+	// The shorthand and the equivalent explicit set extract the same patterns
+	shorthand := NewOverlappingModel(inputImg, 3, 48, 48, true, true, 4, true)
+	explicit, err := NewOverlappingModelWithTransforms(inputImg, 3, 48, 48, true, true, Identity|FlipX|Rotate90|AntiTranspose, true)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if shorthand.T != explicit.T || shorthand.Ground != explicit.Ground {
+		t.Log("Explicit transforms do not match the symmetry shorthand.")
+		t.FailNow()
+	}
+
+	// Rotations only must not contain the mirrored patterns
+	rotations, err := NewOverlappingModelWithTransforms(inputImg, 3, 48, 48, true, true, Rotations, true)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	all := NewOverlappingModel(inputImg, 3, 48, 48, true, true, 8, true)
+	if rotations.T >= all.T {
+		t.Log("Rotations only should extract fewer patterns than all transforms.")
+		t.FailNow()
+	}
+
+	for _, invalid := range []Transforms{0, FlipX | Rotate90} {
+		if _, err := NewOverlappingModelWithTransforms(inputImg, 3, 48, 48, true, true, invalid, true); err == nil {
+			t.Logf("Transform set %08b should be rejected.", uint8(invalid))
+			t.FailNow()
+		}
+	}
+}
+
+func TestOverlappingPatternGrid(t *testing.T) {
+	inputImg, err := testutils.LoadImage("internal/input/flowers.png")
+	if err != nil {
+		panic(err)
+	}
+
+	model := NewOverlappingModel(inputImg, 3, 24, 24, true, true, 2, true)
+	model.SetSeed(42)
+	model.Iterate(5)
+	undecided := 0
+	for _, column := range model.PatternGrid() {
+		for _, p := range column {
+			if p == -1 {
+				undecided++
+			}
+		}
+	}
+	if undecided == 0 {
+		t.Log("Expected undecided slots in an incomplete generation.")
+		t.FailNow()
+	}
+
+	outputImg, success := model.Generate()
+	if !success {
+		t.Log("Failed to generate image on the first try.")
+		t.FailNow()
+	}
+	grid := model.PatternGrid()
+	if len(grid) != 24 || len(grid[0]) != 24 {
+		t.Logf("Expected a 24x24 grid, got %dx%d.", len(grid), len(grid[0]))
+		t.FailNow()
+	}
+	for x, column := range grid {
+		for y, p := range column {
+			if p == -1 {
+				t.Logf("Slot (%d, %d) is undecided after a successful generation.", x, y)
+				t.FailNow()
+			}
+			if model.Colors[model.Patterns[p][0]] != outputImg.At(x, y) {
+				t.Logf("Pattern %d does not match the color at (%d, %d).", p, x, y)
+				t.FailNow()
+			}
+		}
+	}
+}
+
+func TestOverlappingWaveInspection(t *testing.T) {
+	inputImg, err := testutils.LoadImage("internal/input/flowers.png")
+	if err != nil {
+		panic(err)
+	}
+
+	model := NewOverlappingModel(inputImg, 3, 24, 24, true, true, 2, false)
+	model.SetSeed(42)
+	model.Clear()
+	if model.CollapsedCount() != 0 || len(model.Possibilities(0, 0)) != model.T || model.Entropy(0, 0) <= 0 {
+		t.Log("Expected every pattern to be possible before the first observation.")
+		t.FailNow()
+	}
+
+	previous := 0
+	for i := 0; i < 20; i++ {
+		if _, finished, _ := model.Iterate(1); finished {
+			t.Log("Generation finished too early.")
+			t.FailNow()
+		}
+		collapsed := model.CollapsedCount()
+		if collapsed <= previous {
+			t.Logf("Expected more collapsed slots after iteration %d, got %d.", i, collapsed)
+			t.FailNow()
+		}
+		previous = collapsed
+	}
+
+	for x := 0; x < model.Fmx; x++ {
+		for y := 0; y < model.Fmy; y++ {
+			possibilities := model.Possibilities(x, y)
+			if model.IsCollapsed(x, y) != (len(possibilities) == 1) {
+				t.Logf("Slot (%d, %d) has %d possibilities, inconsistent with IsCollapsed.", x, y, len(possibilities))
+				t.FailNow()
+			}
+			if model.IsCollapsed(x, y) && model.Entropy(x, y) != 0 {
+				t.Logf("Collapsed slot (%d, %d) has entropy %v.", x, y, model.Entropy(x, y))
+				t.FailNow()
+			}
+		}
+	}
+}
+
+func TestOverlappingSetSize(t *testing.T) {
+	inputImg, err := testutils.LoadImage("internal/input/flowers.png")
+	if err != nil {
+		panic(err)
+	}
+
+	// A preview, then the size of the snapshot from the same model
+	model := NewOverlappingModel(inputImg, 3, 16, 16, true, true, 2, true)
+	model.SetSeed(7)
+	if preview, success := model.Generate(); !success || preview.Bounds().Dx() != 16 {
+		t.Log("Failed to generate the preview.")
+		t.FailNow()
+	}
+	if err := model.SetSize(48, 48); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	model.SetSeed(42)
+	outputImg, success := model.Generate()
+	if !success {
+		t.Log("Failed to generate image on the first try.")
+		t.FailNow()
+	}
+	snapshotImg, err := testutils.LoadImage("internal/snapshots/flowers.png")
+	if err != nil {
+		panic(err)
+	}
+	if !testutils.CompareImages(outputImg, snapshotImg) {
+		t.Log("Output image is not the same as the snapshot image.")
+		t.FailNow()
+	}
+
+	// The state is kept when the size does not change
+	wave := &model.Wave[0][0][0]
+	if err := model.SetSize(48, 48); err != nil || &model.Wave[0][0][0] != wave || !model.IsGenerationSuccessful() {
+		t.Log("Setting the same size should keep the state.")
+		t.FailNow()
+	}
+
+	nonPeriodic := NewOverlappingModel(inputImg, 3, 16, 16, true, false, 2, false)
+	for _, size := range [][2]int{{0, 16}, {2, 16}} {
+		if err := nonPeriodic.SetSize(size[0], size[1]); err == nil || nonPeriodic.Fmx != 16 || nonPeriodic.Fmxmn != 13 {
+			t.Logf("Size %dx%d should be rejected, leaving the model unchanged.", size[0], size[1])
+			t.FailNow()
+		}
+	}
+}
@@ -1,5 +1,13 @@
 package wfc
 
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
 func randomIndice(array []float64, r float64) int {
 	sum := 0.0
 
@@ -31,3 +39,123 @@ func randomIndice(array []float64, r float64) int {
 
 	return 0
 }
+
+/**
+ * Source Type. Generator of uniformly distributed random numbers, e.g. *rand.PCG or *rand.ChaCha8 of math/rand/v2.
+ * Sources that also implement encoding.BinaryMarshaler and encoding.BinaryUnmarshaler can be saved and restored
+ * with RandomState and SetRandomState.
+ */
+type Source interface {
+	Uint64() uint64
+}
+
+// Tags of the serialized random state
+const (
+	seedState   byte = 's' // Seed and count of draws, for the default generator
+	sourceState byte = 'x' // State of a source set by the user
+)
+
+// Seed the default generator, counting the numbers drawn so that its state can be saved
+func (baseModel *BaseModel) seedRng(seed int64) {
+	baseModel.Seed = seed
+	baseModel.draws = 0
+	if baseModel.Deterministic {
+		// The algorithm of math/rand may change between versions of Go, so pin it
+		state := splitMix64(seed)
+		baseModel.rng64 = func() uint64 {
+			baseModel.draws++
+			return state.Uint64()
+		}
+		baseModel.Rng = func() float64 {
+			return unitFloat(baseModel.rng64())
+		}
+		return
+	}
+	rng := rand.New(rand.NewSource(seed))
+	baseModel.rng64 = nil
+	baseModel.Rng = func() float64 {
+		baseModel.draws++
+		return rng.Float64()
+	}
+}
+
+/**
+ * Use the given source for the random number generator, instead of seeds. Unlike seeds, the source is not
+ * reset when clearing, so that each generation carries on from the state left by the previous one.
+ * BaseModel.Seed is left unchanged and does not describe the generations drawing from the source.
+ */
+func (baseModel *BaseModel) SetSource(source Source) {
+	baseModel.source = source
+	baseModel.RngSet = true
+	baseModel.rng64 = source.Uint64
+	baseModel.Rng = func() float64 {
+		return unitFloat(source.Uint64())
+	}
+}
+
+// Convert random bits to a float64 in [0, 1), as math/rand/v2 does: the 53 high bits, as a fraction of 2^53
+func unitFloat(bits uint64) float64 {
+	return float64(bits>>11) / (1 << 53)
+}
+
+/**
+ * Save the state of the random number generator, to resume a paused generation with SetRandomState
+ * returns: the state, or an error if the source set by the user cannot be saved
+ */
+func (baseModel *BaseModel) RandomState() ([]byte, error) {
+	if baseModel.source != nil {
+		marshaler, ok := baseModel.source.(encoding.BinaryMarshaler)
+		if !ok {
+			return nil, fmt.Errorf("wfc: random source %T cannot be saved, it does not implement encoding.BinaryMarshaler", baseModel.source)
+		}
+		state, err := marshaler.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("wfc: saving random source: %w", err)
+		}
+		return append([]byte{sourceState}, state...), nil
+	}
+	if baseModel.Rng == nil {
+		return nil, errors.New("wfc: no random state before the first generation")
+	}
+	state := make([]byte, 17)
+	state[0] = seedState
+	binary.BigEndian.PutUint64(state[1:], uint64(baseModel.Seed))
+	binary.BigEndian.PutUint64(state[9:], baseModel.draws)
+	return state, nil
+}
+
+/**
+ * Restore the state of the random number generator saved by RandomState.
+ * The state of a source is restored into the source set by the user, which must be of the same type.
+ * Fixed seeds and sources of following generations are unchanged.
+ */
+func (baseModel *BaseModel) SetRandomState(state []byte) error {
+	if len(state) == 0 {
+		return errors.New("wfc: empty random state")
+	}
+	switch state[0] {
+	case sourceState:
+		unmarshaler, ok := baseModel.source.(encoding.BinaryUnmarshaler)
+		if !ok {
+			return errors.New("wfc: random state of a source needs a source implementing encoding.BinaryUnmarshaler, see SetSource")
+		}
+		if err := unmarshaler.UnmarshalBinary(state[1:]); err != nil {
+			return fmt.Errorf("wfc: restoring random source: %w", err)
+		}
+	case seedState:
+		if baseModel.source != nil {
+			return errors.New("wfc: random state of a seed cannot be restored into a source")
+		}
+		if len(state) != 17 {
+			return fmt.Errorf("wfc: random state of a seed has %d bytes, expected 17", len(state))
+		}
+		draws := binary.BigEndian.Uint64(state[9:])
+		baseModel.seedRng(int64(binary.BigEndian.Uint64(state[1:])))
+		for i := uint64(0); i < draws; i++ {
+			baseModel.Rng()
+		}
+	default:
+		return fmt.Errorf("wfc: unknown random state %q", state[0])
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package wfc
+
+import (
+	"github.com/shawnridgeway/wfc/internal/testutils"
+	"math/rand/v2"
+	"testing"
+)
+
+// Copy of the wave, to pause a generation
+func copyWave(wave [][][]bool) [][][]bool {
+	result := make([][][]bool, len(wave))
+	for x := range wave {
+		result[x] = make([][]bool, len(wave[x]))
+		for y := range wave[x] {
+			result[x][y] = append([]bool(nil), wave[x][y]...)
+		}
+	}
+	return result
+}
+
+func TestRandomStateResumes(t *testing.T) {
+	data := initiateData("castle_data.json")
+	for name, setup := range map[string]func(Model){
+		"seed":   func(model Model) { model.SetSeed(42) },
+		"pcg":    func(model Model) { model.SetSource(rand.NewPCG(1, 2)) },
+		"chacha": func(model Model) { model.SetSource(rand.NewChaCha8([32]byte{42})) },
+	} {
+		model := NewSimpleTiledModel(data, 10, 10, false)
+		setup(model)
+		model.Iterate(10)
+		wave := copyWave(model.Wave)
+		state, err := model.RandomState()
+		if err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+		expected, finished, _ := model.Iterate(1000)
+		if !finished {
+			t.Logf("%s: Failed to finish the generation.", name)
+			t.FailNow()
+		}
+
+		// Resume in another model from the saved wave and random state
+		resumed := NewSimpleTiledModel(data, 10, 10, false)
+		setup(resumed)
+		resumed.Clear()
+		resumed.Wave = wave
+		if err := resumed.SetRandomState(state); err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+		output, _, _ := resumed.Iterate(1000)
+		if !testutils.CompareImages(expected, output) {
+			t.Logf("%s: Resumed generation differs from the original.", name)
+			t.FailNow()
+		}
+	}
+}
+
+// Source without a serializable state
+type counterSource uint64
+
+func (source *counterSource) Uint64() uint64 {
+	*source += 0x9e3779b97f4a7c15
+	return uint64(*source)
+}
+
+func TestRandomStateErrors(t *testing.T) {
+	model := NewSimpleTiledModel(initiateData("castle_data.json"), 10, 10, false)
+	if _, err := model.RandomState(); err == nil {
+		t.Log("Expected an error before the first generation.")
+		t.FailNow()
+	}
+
+	model.SetSeed(42)
+	state, _ := model.RandomState()
+	model.SetSource(new(counterSource))
+	if _, success := model.Generate(); !success {
+		t.Log("Failed to generate image with a custom source.")
+		t.FailNow()
+	}
+	if _, err := model.RandomState(); err == nil {
+		t.Log("Expected an error saving a source without a serializable state.")
+		t.FailNow()
+	}
+	for _, invalid := range [][]byte{nil, state, {'?'}} {
+		if err := model.SetRandomState(invalid); err == nil {
+			t.Logf("Expected an error restoring %v.", invalid)
+			t.FailNow()
+		}
+	}
+}
+
+func TestGenerateWithSeedKeepsSource(t *testing.T) {
+	data := initiateData("castle_data.json")
+	source := rand.NewPCG(1, 2)
+	model := NewSimpleTiledModel(data, 10, 10, false)
+	model.SetSource(source)
+	model.GenerateWithSeed(5)
+	before, _ := source.MarshalBinary()
+	model.Generate()
+	after, _ := source.MarshalBinary()
+	if string(before) == string(after) {
+		t.Log("The source was not used after generating with a seed.")
+		t.FailNow()
+	}
+
+	// The saved state is the one of the source, so that the generation can be replayed
+	state, err := model.RandomState()
+	if err != nil || state[0] != sourceState {
+		t.Logf("Expected the state of the source, got %v (%v).", state, err)
+		t.FailNow()
+	}
+}
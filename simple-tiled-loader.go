@@ -0,0 +1,197 @@
+package wfc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/png"
+	"io/fs"
+	"path"
+	"strconv"
+)
+
+// Tileset description as stored in a JSON data file
+type jsonTiledData struct {
+	Path              string                 `json:"path"`              // Path to tiles, relative to the data file
+	Unique            bool                   `json:"unique"`            // Default to false
+	TileSize          int                    `json:"tileSize"`          // Default to 16, unless tileWidth and tileHeight are given
+	TileWidth         int                    `json:"tileWidth"`         // Default to TileSize
+	TileHeight        int                    `json:"tileHeight"`        // Default to TileSize
+	Tiles             []jsonTile             `json:"tiles"`             // List of all possible tiles, not including inversions
+	Neighbors         []jsonNeighbor         `json:"neighbors"`         // List of possible connections between tiles
+	VerticalNeighbors []jsonVerticalNeighbor `json:"verticalNeighbors"` // List of possible connections between tiles, one above the other
+	DiagonalNeighbors []jsonDiagonalNeighbor `json:"diagonalNeighbors"` // List of possible connections between diagonal tiles
+	BigTiles          []jsonBigTile          `json:"bigTiles"`          // List of tiles spanning several slots
+	Subsets           map[string][]string    `json:"subsets"`           // Named lists of tile names
+	NoRotation        bool                   `json:"noRotation"`        // Default to false
+}
+
+// Raw information on a tile, as stored in a JSON data file
+type jsonTile struct {
+	Name           string   `json:"name"`           // Name used to identify the tile, and to find its image
+	Symmetry       string   `json:"symmetry"`       // Default to ""
+	Weight         float64  `json:"weight"`         // Default to 1
+	ConstraintOnly bool     `json:"constraintOnly"` // Default to false
+	Alternates     []string `json:"alternates"`     // Names of the images of other looks of the tile
+}
+
+// Raw information on a tile spanning several slots, as stored in a JSON data file
+type jsonBigTile struct {
+	Name   string  `json:"name"`   // Name used to identify the tile, and to find its image
+	Width  int     `json:"width"`  // Width in slots
+	Height int     `json:"height"` // Height in slots
+	Weight float64 `json:"weight"` // Default to 1
+}
+
+// Information on which tiles can be neighbors, as stored in a JSON data file
+type jsonNeighbor struct {
+	Left     string `json:"left"`     // Matches Tile.Name
+	LeftNum  int    `json:"leftNum"`  // Default to 0
+	Right    string `json:"right"`    // Matches Tile.Name
+	RightNum int    `json:"rightNum"` // Default to 0
+}
+
+// Information on which tiles can be above one another, as stored in a JSON data file
+type jsonVerticalNeighbor struct {
+	Top       string `json:"top"`       // Matches Tile.Name
+	TopNum    int    `json:"topNum"`    // Default to 0
+	Bottom    string `json:"bottom"`    // Matches Tile.Name
+	BottomNum int    `json:"bottomNum"` // Default to 0
+}
+
+// Information on which tiles can touch diagonally, as stored in a JSON data file
+type jsonDiagonalNeighbor struct {
+	Top       string `json:"top"`       // Matches Tile.Name
+	TopNum    int    `json:"topNum"`    // Default to 0
+	Bottom    string `json:"bottom"`    // Matches Tile.Name
+	BottomNum int    `json:"bottomNum"` // Default to 0
+	Leftward  bool   `json:"leftward"`  // Default to false
+}
+
+/**
+ * LoadSimpleTiledData
+ * Read a JSON tileset description and the images it refers to.
+ * Images are looked up as "<path><name>.png", relative to the directory of the data file. When the tileset
+ * is unique, every image "<path><name> 1.png" through "<path><name> k.png" is loaded instead.
+ * @param {fs.FS} fsys File system holding the data file and the images
+ * @param {string} name Path of the JSON data file within fsys
+ * @return SimpleTiledData The parsed tiles and constraints
+ * @return error Non-nil if the data file or one of the images cannot be read
+ */
+func LoadSimpleTiledData(fsys fs.FS, name string) (SimpleTiledData, error) {
+	raw, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return SimpleTiledData{}, fmt.Errorf("wfc: reading tileset: %w", err)
+	}
+
+	var rawData jsonTiledData
+	if err := json.Unmarshal(raw, &rawData); err != nil {
+		return SimpleTiledData{}, fmt.Errorf("wfc: parsing tileset %s: %w", name, err)
+	}
+
+	tileSize := rawData.TileSize
+	// The width and height, when both given, replace the default size
+	if tileSize == 0 && (rawData.TileWidth == 0 || rawData.TileHeight == 0) {
+		tileSize = 16
+	}
+
+	dir := path.Join(path.Dir(name), rawData.Path)
+	tiles := make([]Tile, len(rawData.Tiles))
+	// Images of a tile, or of one of its alternates
+	loadVariants := func(imageName string) ([]image.Image, error) {
+		imgs := make([]image.Image, 0)
+		if !rawData.Unique {
+			img, err := loadImage(fsys, path.Join(dir, imageName+".png"))
+			if err != nil {
+				return nil, err
+			}
+			return append(imgs, img), nil
+		}
+		for k := 1; ; k++ {
+			img, err := loadImage(fsys, path.Join(dir, imageName+" "+strconv.Itoa(k)+".png"))
+			if errors.Is(err, fs.ErrNotExist) {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			imgs = append(imgs, img)
+		}
+		if len(imgs) == 0 {
+			return nil, fmt.Errorf("missing image %s", path.Join(dir, imageName+" 1.png"))
+		}
+		return imgs, nil
+	}
+
+	for i, rt := range rawData.Tiles {
+		imgs, err := loadVariants(rt.Name)
+		if err != nil {
+			return SimpleTiledData{}, fmt.Errorf("wfc: tile %q (index %d): %w", rt.Name, i, err)
+		}
+		var alternates [][]image.Image
+		for _, alternate := range rt.Alternates {
+			alternateImgs, err := loadVariants(alternate)
+			if err != nil {
+				return SimpleTiledData{}, fmt.Errorf("wfc: tile %q (index %d) alternate %q: %w", rt.Name, i, alternate, err)
+			}
+			alternates = append(alternates, alternateImgs)
+		}
+		weight := rt.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		tiles[i] = Tile{Name: rt.Name, Symmetry: rt.Symmetry, Weight: weight, Variants: imgs, ConstraintOnly: rt.ConstraintOnly, Alternates: alternates}
+	}
+
+	bigTiles := make([]BigTile, len(rawData.BigTiles))
+	for i, rt := range rawData.BigTiles {
+		img, err := loadImage(fsys, path.Join(dir, rt.Name+".png"))
+		if err != nil {
+			return SimpleTiledData{}, fmt.Errorf("wfc: big tile %q (index %d): %w", rt.Name, i, err)
+		}
+		bigTiles[i] = BigTile{Name: rt.Name, Width: rt.Width, Height: rt.Height, Weight: rt.Weight, Image: img}
+	}
+
+	neighbors := make([]Neighbor, len(rawData.Neighbors))
+	for i, rn := range rawData.Neighbors {
+		neighbors[i] = Neighbor{Left: rn.Left, LeftNum: rn.LeftNum, Right: rn.Right, RightNum: rn.RightNum}
+	}
+
+	verticalNeighbors := make([]VerticalNeighbor, len(rawData.VerticalNeighbors))
+	for i, rn := range rawData.VerticalNeighbors {
+		verticalNeighbors[i] = VerticalNeighbor{Top: rn.Top, TopNum: rn.TopNum, Bottom: rn.Bottom, BottomNum: rn.BottomNum}
+	}
+
+	diagonalNeighbors := make([]DiagonalNeighbor, len(rawData.DiagonalNeighbors))
+	for i, rn := range rawData.DiagonalNeighbors {
+		diagonalNeighbors[i] = DiagonalNeighbor{Top: rn.Top, TopNum: rn.TopNum, Bottom: rn.Bottom, BottomNum: rn.BottomNum, Leftward: rn.Leftward}
+	}
+
+	return SimpleTiledData{
+		Unique:            rawData.Unique,
+		TileSize:          tileSize,
+		TileWidth:         rawData.TileWidth,
+		TileHeight:        rawData.TileHeight,
+		Tiles:             tiles,
+		Neighbors:         neighbors,
+		VerticalNeighbors: verticalNeighbors,
+		DiagonalNeighbors: diagonalNeighbors,
+		BigTiles:          bigTiles,
+		Subsets:           rawData.Subsets,
+		NoRotation:        rawData.NoRotation,
+	}, nil
+}
+
+// Decode the image stored at name
+func loadImage(fsys fs.FS, name string) (image.Image, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("decoding image %s: %w", name, err)
+	}
+	return img, nil
+}
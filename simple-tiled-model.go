@@ -1,7 +1,7 @@
 package wfc
 
 import (
-	// "fmt"
+	"fmt"
 	"image"
 	"image/color"
 )
@@ -10,26 +10,44 @@ import (
  * SimpleTiledModel Type
  */
 type SimpleTiledModel struct {
-	*BaseModel               // Underlying model of generic Wave Function Collapse algorithm
-	TileSize   int           // The size in pixels of the length and height of each tile
-	Tiles      []TilePattern // List of all possible tiles as images, including inversions
-	Propagator [][][]bool    // All possible connections between tiles
+	*BaseModel                   // Underlying model of generic Wave Function Collapse algorithm
+	TileSize     int             // The size in pixels of the length and height of each tile, when square
+	TileWidth    int             // The width in pixels of each tile
+	TileHeight   int             // The height in pixels of each tile
+	Tiles        []TilePattern   // List of all possible tiles as images, including inversions
+	Propagator   [][][]bool      // All possible connections between tiles
+	Alternates   [][]TilePattern // Other looks of each tile, including inversions, chosen per slot when rendering
+	Orientations []TileRef       // Tile and number of each tile, including inversions, as in Neighbor
+	// Seed choosing among the looks of each tile. The same seed always gives the same look to the same slot
+	AlternateSeed int64
+	linked        [][]bool // Whether each tile is part of a big tile continuing in each direction [d][t], if there are big tiles
 }
 
 // Parsed data supplied by user
 type SimpleTiledData struct {
-	Unique    bool       // False if each tile can have variants. (Default to false?)
-	TileSize  int        // Default to 16
-	Tiles     []Tile     // List of all possible tiles, not including inversions
-	Neighbors []Neighbor // List of possible connections between tiles
+	Unique            bool                // False if each tile can have variants. (Default to false?)
+	TileSize          int                 // Default to 16
+	TileWidth         int                 // Width in pixels of tiles that are not square. Default to TileSize
+	TileHeight        int                 // Height in pixels of tiles that are not square. Default to TileSize
+	Tiles             []Tile              // List of all possible tiles, not including inversions
+	Neighbors         []Neighbor          // List of possible connections between tiles
+	VerticalNeighbors []VerticalNeighbor  // List of possible connections between tiles, one above the other
+	DiagonalNeighbors []DiagonalNeighbor  // List of possible connections between diagonal tiles. Default to none, leaving diagonal tiles unconstrained
+	BigTiles          []BigTile           // List of tiles spanning several slots, split into parts when constructing the model
+	Subsets           map[string][]string // Named lists of tile names that can be used instead of the whole tileset
+	NoRotation        bool                // Only use tiles as drawn, never rotated nor mirrored, and apply constraints as written
 }
 
 // Raw information on a tile
 type Tile struct {
-	Name     string        // Name used to identify the tile
-	Symmetry string        // Default to ""
-	Weight   float64       // Default to 1
-	Variants []image.Image // Preloaded image for the tile
+	Name           string        // Name used to identify the tile
+	Symmetry       string        // Default to ""
+	Weight         float64       // Default to 1, when unset (0)
+	Variants       []image.Image // Preloaded image for the tile
+	ConstraintOnly bool          // Never chosen when observing, only placed when constraints leave no other option
+	// Other looks of the tile, each laid out like Variants, that do not change the constraints.
+	// One of Variants and Alternates is chosen for each slot when rendering (see SimpleTiledModel.AlternateSeed)
+	Alternates [][]image.Image
 }
 
 // Information on which tiles can be neighbors
@@ -40,6 +58,38 @@ type Neighbor struct {
 	RightNum int    // Default to 0
 }
 
+/**
+ * Information on which tiles can be above one another.
+ * Unless NoRotation is set, constraints are rotated and mirrored along with the tiles, so every vertical
+ * constraint is also a horizontal one and the other way around.
+ */
+type VerticalNeighbor struct {
+	Top       string // Matches Tile.Name
+	TopNum    int    // Default to 0
+	Bottom    string // Matches Tile.Name
+	BottomNum int    // Default to 0
+}
+
+/**
+ * Information on which tiles can touch diagonally, the bottom tile being below and to the right of the top tile.
+ * Diagonal constraints are rotated and mirrored like the others, unless NoRotation is set.
+ */
+type DiagonalNeighbor struct {
+	Top       string // Matches Tile.Name
+	TopNum    int    // Default to 0
+	Bottom    string // Matches Tile.Name
+	BottomNum int    // Default to 0
+	Leftward  bool   // True if the bottom tile is below and to the left of the top tile instead
+}
+
+// Direction of the bottom tile from the top tile (see tileDirections)
+func (neighbor DiagonalNeighbor) direction() int {
+	if neighbor.Leftward {
+		return 4
+	}
+	return 5
+}
+
 // Flat array of colors in a tile
 type TilePattern []color.Color
 
@@ -48,6 +98,8 @@ type Inversion func(int) int
 
 /**
  * NewSimpleTiledModel
+ * The data is not validated: unknown symmetries panic, and other invalid input may panic or produce a wrong model.
+ * Use NewSimpleTiledModelChecked to get an error instead.
  * @param {object} data Tiles and constraints definitions
  * @param {int} width The width of the generation, in terms of tiles (not pixels)
  * @param {int} height The height of the generation, in terms of tiles (not pixels)
@@ -62,179 +114,242 @@ func NewSimpleTiledModel(data SimpleTiledData, width, height int, periodic bool)
 	model.Fmy = height
 	model.Periodic = periodic
 	model.TileSize = data.TileSize
-	model.Tiles = make([]TilePattern, 0)
+	model.TileWidth, model.TileHeight = data.tileBounds()
 	model.Stationary = make([]float64, 0)
 
-	firstOccurrence := make(map[string]int)
-	action := make([][]int, 0)
+	data, parts := data.withBigTiles()
+	orientations := newTileOrientations(data.Tiles, data.transforms())
 
-	tile := func(transformer func(x, y int) color.Color) TilePattern {
-		result := make(TilePattern, model.TileSize*model.TileSize)
-		for y := 0; y < model.TileSize; y++ {
-			for x := 0; x < model.TileSize; x++ {
-				result[x+y*model.TileSize] = transformer(x, y)
-			}
-		}
-		return result
+	model.Tiles = orientationPatterns(data, orientations)
+	model.Alternates = alternatePatterns(data, orientations)
+	model.Orientations = make([]TileRef, len(orientations.tileIndex))
+	for t := range model.Orientations {
+		i, num := orientations.reference(t)
+		model.Orientations[t] = TileRef{Name: data.Tiles[i].Name, Num: num}
 	}
 
-	rotate := func(p TilePattern) TilePattern {
-		return tile(func(x, y int) color.Color {
-			return p[model.TileSize-1-y+x*model.TileSize]
-		})
+	for _, i := range orientations.tileIndex {
+		weight := data.Tiles[i].Weight
+		if data.Tiles[i].ConstraintOnly {
+			weight = 0
+		} else if weight == 0 {
+			weight = 1
+		}
+		model.Stationary = append(model.Stationary, weight)
 	}
 
-	for i := 0; i < len(data.Tiles); i++ {
-		currentTile := data.Tiles[i]
-		var cardinality int
-		var inversion1, inversion2 Inversion
+	model.T = len(orientations.action)
+	model.Propagator = make([][][]bool, 4)
+	if len(data.DiagonalNeighbors) > 0 {
+		model.Propagator = make([][][]bool, len(tileDirections))
+	}
 
-		switch currentTile.Symmetry {
-		case "L":
-			cardinality = 4
-			inversion1 = func(i int) int {
-				return (i + 1) % 4
-			}
-			inversion2 = func(i int) int {
-				if i%2 == 0 {
-					return i + 1
-				}
-				return i - 1
-			}
-		case "T":
-			cardinality = 4
-			inversion1 = func(i int) int {
-				return (i + 1) % 4
-			}
-			inversion2 = func(i int) int {
-				if i%2 == 0 {
-					return i
-				}
-				return 4 - i
-			}
-		case "I":
-			cardinality = 2
-			inversion1 = func(i int) int {
-				return 1 - i
-			}
-			inversion2 = func(i int) int {
-				return i
-			}
-		case "\\":
-			cardinality = 2
-			inversion1 = func(i int) int {
-				return 1 - i
-			}
-			inversion2 = func(i int) int {
-				return 1 - i
-			}
-		case "X":
-			cardinality = 1
-			inversion1 = func(i int) int {
-				return i
-			}
-			inversion2 = func(i int) int {
-				return i
-			}
-		default:
-			cardinality = 1
-			inversion1 = func(i int) int {
-				return i
-			}
-			inversion2 = func(i int) int {
-				return i
+	for i := range model.Propagator {
+		model.Propagator[i] = make([][]bool, model.T)
+		for t := 0; t < model.T; t++ {
+			model.Propagator[i][t] = make([]bool, model.T)
+			for t2 := 0; t2 < model.T; t2++ {
+				model.Propagator[i][t][t2] = false
 			}
 		}
+	}
+
+	model.allocate()
 
-		model.T = len(action)
-		firstOccurrence[currentTile.Name] = model.T
-
-		for t := 0; t < cardinality; t++ {
-			action = append(action, []int{
-				model.T + t,
-				model.T + inversion1(t),
-				model.T + inversion1(inversion1(t)),
-				model.T + inversion1(inversion1(inversion1(t))),
-				model.T + inversion2(t),
-				model.T + inversion2(inversion1(t)),
-				model.T + inversion2(inversion1(inversion1(t))),
-				model.T + inversion2(inversion1(inversion1(inversion1(t)))),
-			})
+	// Allow every constraint in every orientation of the tiles (see tileOrientations.expand)
+	allow := func(first string, firstNum int, second string, secondNum int, direction int) {
+		a, ok := orientations.orientation(first, firstNum)
+		b, ok2 := orientations.orientation(second, secondNum)
+		if !ok || !ok2 {
+			return
 		}
+		orientations.expand(tileRule{a, b, direction}, func(rule tileRule) {
+			model.Propagator[rule.direction][rule.a][rule.b] = true
+		})
+	}
+	for _, neighbor := range data.Neighbors {
+		allow(neighbor.Left, neighbor.LeftNum, neighbor.Right, neighbor.RightNum, 2)
+	}
+	for _, neighbor := range data.VerticalNeighbors {
+		allow(neighbor.Top, neighbor.TopNum, neighbor.Bottom, neighbor.BottomNum, 1)
+	}
+	for _, neighbor := range data.DiagonalNeighbors {
+		allow(neighbor.Top, neighbor.TopNum, neighbor.Bottom, neighbor.BottomNum, neighbor.direction())
+	}
 
-		if data.Unique {
-			for t := 0; t < cardinality; t++ {
-				img := currentTile.Variants[t]
-				model.Tiles = append(model.Tiles, tile(func(x, y int) color.Color {
-					return img.At(x, y)
-				}))
+	// Parts of big tiles cannot be cut by the edges of a non periodic output
+	if len(parts) > 0 {
+		model.linked = make([][]bool, 4)
+		for d := range model.linked {
+			model.linked[d] = make([]bool, model.T)
+		}
+		for t, i := range orientations.tileIndex {
+			part, ok := parts[data.Tiles[i].Name]
+			if !ok {
+				continue
 			}
-		} else {
-			img := currentTile.Variants[0]
-			model.Tiles = append(model.Tiles, tile(func(x, y int) color.Color {
-				return img.At(x, y)
-			}))
-
-			for t := 1; t < cardinality; t++ {
-				model.Tiles = append(model.Tiles, rotate(model.Tiles[model.T+t-1]))
+			k := orientations.transform(t)
+			for d := range model.linked {
+				model.linked[transformDirection(d, k)][t] = part.linked(d)
 			}
 		}
-
-		for t := 0; t < cardinality; t++ {
-			model.Stationary = append(model.Stationary, currentTile.Weight)
-		}
 	}
 
-	model.T = len(action)
-	model.Propagator = make([][][]bool, 4)
+	return model
+}
 
-	for i := 0; i < 4; i++ {
-		model.Propagator[i] = make([][]bool, model.T)
-		for t := 0; t < model.T; t++ {
-			model.Propagator[i][t] = make([]bool, model.T)
-			for t2 := 0; t2 < model.T; t2++ {
-				model.Propagator[i][t][t2] = false
-			}
-		}
+/**
+ * Offset of the neighbor in each direction of the Propagator, where Propagator[d][t2][t1] allows t1
+ * to be placed at the offset of direction d from t2. Diagonal directions (4 to 7) are only used
+ * by tilesets with diagonal constraints.
+ */
+var tileDirections = []image.Point{{-1, 0}, {0, 1}, {1, 0}, {0, -1}, {-1, 1}, {1, 1}, {1, -1}, {-1, -1}}
+
+// Direction reached by applying the k-th transform (see tileSymmetry.act) to direction d
+func transformDirection(d, k int) int {
+	offset := tileDirections[d]
+	for r := 0; r < k%4; r++ {
+		offset = image.Pt(offset.Y, -offset.X)
 	}
+	if k >= 4 {
+		offset.X = -offset.X
+	}
+	return directionOf(offset)
+}
 
-	model.Wave = make([][][]bool, model.Fmx)
-	model.Changes = make([][]bool, model.Fmx)
-	for x := 0; x < model.Fmx; x++ {
-		model.Wave[x] = make([][]bool, model.Fmy)
-		model.Changes[x] = make([]bool, model.Fmy)
-		for y := 0; y < model.Fmy; y++ {
-			model.Wave[x][y] = make([]bool, model.T)
+// Direction opposite to direction d
+func oppositeDirection(d int) int {
+	return directionOf(tileDirections[d].Mul(-1))
+}
+
+func directionOf(offset image.Point) int {
+	for d, candidate := range tileDirections {
+		if candidate == offset {
+			return d
 		}
 	}
+	return -1
+}
 
-	for i := 0; i < len(data.Neighbors); i++ {
-		neighbor := data.Neighbors[i]
+// Width and height of the tiles in pixels
+func (data SimpleTiledData) tileBounds() (int, int) {
+	width, height := data.TileWidth, data.TileHeight
+	if width == 0 {
+		width = data.TileSize
+	}
+	if height == 0 {
+		height = data.TileSize
+	}
+	return width, height
+}
 
-		l := action[firstOccurrence[neighbor.Left]][neighbor.LeftNum]
-		d := action[l][1]
-		r := action[firstOccurrence[neighbor.Right]][neighbor.RightNum]
-		u := action[r][1]
+// Transforms that can be applied to the tiles, without quarter turns when tiles are not square
+func (data SimpleTiledData) transforms() []int {
+	if data.NoRotation {
+		return []int{0}
+	}
+	if width, height := data.tileBounds(); width != height {
+		return rectangleTransforms
+	}
+	return allTileTransforms
+}
 
-		model.Propagator[0][r][l] = true
-		model.Propagator[0][action[r][6]][action[l][6]] = true
-		model.Propagator[0][action[l][4]][action[r][4]] = true
-		model.Propagator[0][action[l][2]][action[r][2]] = true
+// Build the image of every orientation of every tile
+func orientationPatterns(data SimpleTiledData, orientations tileOrientations) []TilePattern {
+	result := make([]TilePattern, 0, len(orientations.tileIndex))
+	for t, i := range orientations.tileIndex {
+		result = append(result, data.orientationPattern(orientations, t, data.Tiles[i].Variants))
+	}
+	return result
+}
 
-		model.Propagator[1][u][d] = true
-		model.Propagator[1][action[d][6]][action[u][6]] = true
-		model.Propagator[1][action[u][4]][action[d][4]] = true
-		model.Propagator[1][action[d][2]][action[u][2]] = true
+// Build the image of orientation t from images laid out like Tile.Variants
+func (data SimpleTiledData) orientationPattern(orientations tileOrientations, t int, variants []image.Image) TilePattern {
+	width, height := data.tileBounds()
+	if data.Unique {
+		// Variants are given for every orientation of the symmetry class, or only for the reachable ones
+		i, variant := orientations.tileIndex[t], orientations.localIndex[t]
+		if len(variants) < orientations.symmetries[i].cardinality {
+			variant = t - orientations.local[i][0]
+		}
+		return cellPattern(variants[variant], 0, 0, width, height, 0)
 	}
+	return cellPattern(variants[0], 0, 0, width, height, orientations.transform(t))
+}
 
-	for t := 0; t < model.T; t++ {
-		for t2 := 0; t2 < model.T; t2++ {
-			model.Propagator[2][t][t2] = model.Propagator[0][t2][t]
-			model.Propagator[3][t][t2] = model.Propagator[1][t2][t]
+// Build the other looks of every orientation of every tile
+func alternatePatterns(data SimpleTiledData, orientations tileOrientations) [][]TilePattern {
+	result := make([][]TilePattern, 0, len(orientations.tileIndex))
+	for t, i := range orientations.tileIndex {
+		alternates := make([]TilePattern, 0, len(data.Tiles[i].Alternates))
+		for _, variants := range data.Tiles[i].Alternates {
+			alternates = append(alternates, data.orientationPattern(orientations, t, variants))
 		}
+		result = append(result, alternates)
 	}
+	return result
+}
 
-	return model
+/**
+ * NewSimpleTiledModelSubset
+ * Same as NewSimpleTiledModel, but only using the tiles of one of the subsets defined in data.
+ * @param {string} subset Name of the subset, as a key of data.Subsets
+ * @return *SimpleTiledModel A pointer to a new copy of the model
+ * @return error Non-nil if the subset is not defined or the tiles of the subset are invalid
+ */
+func NewSimpleTiledModelSubset(data SimpleTiledData, subset string, width, height int, periodic bool) (*SimpleTiledModel, error) {
+	data, err := data.Subset(subset)
+	if err != nil {
+		return nil, err
+	}
+	return NewSimpleTiledModelChecked(data, width, height, periodic)
+}
+
+/**
+ * Copy the data, keeping only the tiles of the named subset and the neighbors between them
+ */
+func (data SimpleTiledData) Subset(name string) (SimpleTiledData, error) {
+	names, ok := data.Subsets[name]
+	if !ok {
+		return SimpleTiledData{}, fmt.Errorf("wfc: unknown subset %q", name)
+	}
+	included := make(map[string]bool)
+	for _, tileName := range names {
+		included[tileName] = true
+	}
+
+	result := data
+	result.Tiles = make([]Tile, 0, len(names))
+	for _, tile := range data.Tiles {
+		if included[tile.Name] {
+			result.Tiles = append(result.Tiles, tile)
+		}
+	}
+	result.BigTiles = make([]BigTile, 0, len(data.BigTiles))
+	for _, tile := range data.BigTiles {
+		if included[tile.Name] {
+			result.BigTiles = append(result.BigTiles, tile)
+		}
+	}
+	result.Neighbors = make([]Neighbor, 0, len(data.Neighbors))
+	for _, neighbor := range data.Neighbors {
+		if included[neighbor.Left] && included[neighbor.Right] {
+			result.Neighbors = append(result.Neighbors, neighbor)
+		}
+	}
+	result.VerticalNeighbors = make([]VerticalNeighbor, 0, len(data.VerticalNeighbors))
+	for _, neighbor := range data.VerticalNeighbors {
+		if included[neighbor.Top] && included[neighbor.Bottom] {
+			result.VerticalNeighbors = append(result.VerticalNeighbors, neighbor)
+		}
+	}
+	result.DiagonalNeighbors = make([]DiagonalNeighbor, 0, len(data.DiagonalNeighbors))
+	for _, neighbor := range data.DiagonalNeighbors {
+		if included[neighbor.Top] && included[neighbor.Bottom] {
+			result.DiagonalNeighbors = append(result.DiagonalNeighbors, neighbor)
+		}
+	}
+	return result, nil
 }
 
 /**
@@ -253,50 +368,16 @@ func (model *SimpleTiledModel) Propagate() bool {
 
 	for x2 := 0; x2 < model.Fmx; x2++ {
 		for y2 := 0; y2 < model.Fmy; y2++ {
-			for d := 0; d < 4; d++ {
-				x1 := x2
-				y1 := y2
-
-				if d == 0 {
-					if x2 == 0 {
-						if !model.Periodic {
-							continue
-						} else {
-							x1 = model.Fmx - 1
-						}
-					} else {
-						x1 = x2 - 1
-					}
-				} else if d == 1 {
-					if y2 == model.Fmy-1 {
-						if !model.Periodic {
-							continue
-						} else {
-							y1 = 0
-						}
-					} else {
-						y1 = y2 + 1
-					}
-				} else if d == 2 {
-					if x2 == model.Fmx-1 {
-						if !model.Periodic {
-							continue
-						} else {
-							x1 = 0
-						}
-					} else {
-						x1 = x2 + 1
-					}
-				} else {
-					if y2 == 0 {
-						if !model.Periodic {
-							continue
-						} else {
-							y1 = model.Fmy - 1
-						}
-					} else {
-						y1 = y2 - 1
+			for d := range model.Propagator {
+				x1 := x2 + tileDirections[d].X
+				y1 := y2 + tileDirections[d].Y
+
+				if x1 < 0 || x1 >= model.Fmx || y1 < 0 || y1 >= model.Fmy {
+					if !model.Periodic {
+						continue
 					}
+					x1 = (x1 + model.Fmx) % model.Fmx
+					y1 = (y1 + model.Fmy) % model.Fmy
 				}
 
 				if !model.Changes[x1][y1] {
@@ -328,27 +409,49 @@ func (model *SimpleTiledModel) Propagate() bool {
 }
 
 /**
- * Clear the internal state, then set ground pattern
+ * Clear the internal state, then keep big tiles from being cut by the edges of the output
  */
 func (model *SimpleTiledModel) Clear() {
 	model.ClearBase(model)
+	if model.linked != nil && !model.Periodic {
+		for x := 0; x < model.Fmx; x++ {
+			for y := 0; y < model.Fmy; y++ {
+				for d, linked := range model.linked {
+					offset := tileDirections[d]
+					if image.Pt(x, y).Add(offset).In(image.Rect(0, 0, model.Fmx, model.Fmy)) {
+						continue
+					}
+					for t := 0; t < model.T; t++ {
+						if linked[t] {
+							model.Wave[x][y][t] = false
+							model.Changes[x][y] = true
+						}
+					}
+				}
+			}
+		}
+
+		for model.Propagate() {
+			// Empty loop
+		}
+	}
 }
 
 /**
  * Create a image.Image holding the data for a complete image
  */
 func (model *SimpleTiledModel) RenderCompleteImage() image.Image {
-	output := make([][]color.Color, model.Fmx*model.TileSize)
+	output := make([][]color.Color, model.Fmx*model.TileWidth)
 	for i := range output {
-		output[i] = make([]color.Color, model.Fmy*model.TileSize)
+		output[i] = make([]color.Color, model.Fmy*model.TileHeight)
 	}
 	for y := 0; y < model.Fmy; y++ {
 		for x := 0; x < model.Fmx; x++ {
-			for yt := 0; yt < model.TileSize; yt++ {
-				for xt := 0; xt < model.TileSize; xt++ {
+			for yt := 0; yt < model.TileHeight; yt++ {
+				for xt := 0; xt < model.TileWidth; xt++ {
 					for t := 0; t < model.T; t++ {
 						if model.Wave[x][y][t] {
-							output[x*model.TileSize+xt][y*model.TileSize+yt] = model.Tiles[t][yt*model.TileSize+xt]
+							output[x*model.TileWidth+xt][y*model.TileHeight+yt] = model.look(t, x, y)[yt*model.TileWidth+xt]
 							break
 						}
 					}
@@ -359,44 +462,150 @@ func (model *SimpleTiledModel) RenderCompleteImage() image.Image {
 	return GeneratedImage{output}
 }
 
+/**
+ * SetSize
+ * Change the size of the generated image, keeping the tiles and propagator.
+ * The state of the generation is only reallocated if the size is different, and is cleared in that case.
+ * @param {int} width The width of the generated image, in tiles
+ * @param {int} height The height of the generated image, in tiles
+ * @return error A *ValidationError if the size is invalid, in which case the model is unchanged
+ */
+func (model *SimpleTiledModel) SetSize(width, height int) error {
+	v := &validator{subject: "size"}
+	validateOutputSize(v, width, height)
+	if err := v.result(); err != nil {
+		return err
+	}
+	model.resize(width, height)
+	return nil
+}
+
+/**
+ * Reskin
+ * Replace the images of the tiles by those of another tileset, keeping the state of the generation.
+ * Tiles are matched by name, so the theme needs the same tile names, but can have other images and another tile size.
+ * Its constraints are ignored.
+ * @param {SimpleTiledData} theme The tileset holding the new images
+ * @return error A *ValidationError if the theme is invalid or lacks a tile, in which case the model is unchanged
+ */
+func (model *SimpleTiledModel) Reskin(theme SimpleTiledData) error {
+	theme.Neighbors, theme.VerticalNeighbors, theme.DiagonalNeighbors = nil, nil, nil
+	v := &validator{subject: "theme"}
+	theme.validate(v)
+	if err := v.result(); err != nil {
+		return err
+	}
+
+	theme, _ = theme.withBigTiles()
+	orientations := newTileOrientations(theme.Tiles, theme.transforms())
+	tiles := make([]TilePattern, len(model.Orientations))
+	alternates := make([][]TilePattern, len(model.Orientations))
+	reported := make(map[TileRef]bool)
+	for t, ref := range model.Orientations {
+		themed, ok := orientations.orientation(ref.Name, ref.Num)
+		if !ok {
+			if _, known := orientations.tileNames[ref.Name]; !known && !reported[TileRef{Name: ref.Name}] {
+				v.add("tile %q is missing", ref.Name)
+			} else if known {
+				v.add("tile %q cannot be transformed by number %d", ref.Name, ref.Num)
+			}
+			reported[TileRef{Name: ref.Name}] = true
+			continue
+		}
+		tile := theme.Tiles[orientations.tileIndex[themed]]
+		tiles[t] = theme.orientationPattern(orientations, themed, tile.Variants)
+		for _, variants := range tile.Alternates {
+			alternates[t] = append(alternates[t], theme.orientationPattern(orientations, themed, variants))
+		}
+	}
+	if err := v.result(); err != nil {
+		return err
+	}
+
+	model.Tiles = tiles
+	model.Alternates = alternates
+	model.TileSize = theme.TileSize
+	model.TileWidth, model.TileHeight = theme.tileBounds()
+	return nil
+}
+
+// Look of tile t in slot (x, y), among the tile and its alternates
+func (model *SimpleTiledModel) look(t, x, y int) TilePattern {
+	if t >= len(model.Alternates) || len(model.Alternates[t]) == 0 {
+		return model.Tiles[t]
+	}
+	// Mix the seed and the slot (see splitmix64) so that neighboring slots get unrelated looks
+	h := uint64(model.AlternateSeed) ^ uint64(x)*0x9e3779b97f4a7c15 ^ uint64(y)*0xc2b2ae3d27d4eb4f
+	h = (h ^ (h >> 30)) * 0xbf58476d1ce4e5b9
+	h = (h ^ (h >> 27)) * 0x94d049bb133111eb
+	h ^= h >> 31
+	choice := int(h % uint64(len(model.Alternates[t])+1))
+	if choice == 0 {
+		return model.Tiles[t]
+	}
+	return model.Alternates[t][choice-1]
+}
+
 /**
  * Create a image.Image holding the data for an incomplete image
  */
 func (model *SimpleTiledModel) RenderIncompleteImage() image.Image {
-	output := make([][]color.Color, model.Fmx*model.TileSize)
+	output := make([][]color.Color, model.Fmx*model.TileWidth)
 	for i := range output {
-		output[i] = make([]color.Color, model.Fmy*model.TileSize)
+		output[i] = make([]color.Color, model.Fmy*model.TileHeight)
 	}
 	for y := 0; y < model.Fmy; y++ {
 		for x := 0; x < model.Fmx; x++ {
 			amount := 0
 			sum := 0.0
+			chosen := -1
 			for t := 0; t < len(model.Wave[x][y]); t++ {
 				if model.Wave[x][y][t] {
 					amount += 1
 					sum += model.Stationary[t]
+					chosen = t
 				}
 			}
-			for yt := 0; yt < model.TileSize; yt++ {
-				for xt := 0; xt < model.TileSize; xt++ {
+			// Collapsed slots look as they will in the complete image
+			if amount == 1 {
+				pattern := model.look(chosen, x, y)
+				for yt := 0; yt < model.TileHeight; yt++ {
+					for xt := 0; xt < model.TileWidth; xt++ {
+						output[x*model.TileWidth+xt][y*model.TileHeight+yt] = pattern[yt*model.TileWidth+xt]
+					}
+				}
+				continue
+			}
+			// Blend evenly when only constraint-only tiles remain
+			weight := func(t int) float64 {
+				return model.Stationary[t]
+			}
+			if sum == 0 {
+				sum = float64(amount)
+				weight = func(t int) float64 {
+					return 1
+				}
+			}
+			for yt := 0; yt < model.TileHeight; yt++ {
+				for xt := 0; xt < model.TileWidth; xt++ {
 					if amount == model.T {
-						output[x*model.TileSize+xt][y*model.TileSize+yt] = color.RGBA{127, 127, 127, 255}
+						output[x*model.TileWidth+xt][y*model.TileHeight+yt] = color.RGBA{127, 127, 127, 255}
 					} else {
 						sR, sG, sB, sA := 0.0, 0.0, 0.0, 0.0
 						for t := 0; t < model.T; t++ {
 							if model.Wave[x][y][t] {
-								r, g, b, a := model.Tiles[t][yt*model.TileSize+xt].RGBA()
-								sR += float64(r) * model.Stationary[t]
-								sG += float64(g) * model.Stationary[t]
-								sB += float64(b) * model.Stationary[t]
-								sA += float64(a) * model.Stationary[t]
+								r, g, b, a := model.Tiles[t][yt*model.TileWidth+xt].RGBA()
+								sR += float64(r) * weight(t)
+								sG += float64(g) * weight(t)
+								sB += float64(b) * weight(t)
+								sA += float64(a) * weight(t)
 							}
 						}
 						uR := uint8(int(sR/sum) >> 8)
 						uG := uint8(int(sG/sum) >> 8)
 						uB := uint8(int(sB/sum) >> 8)
 						uA := uint8(int(sA/sum) >> 8)
-						output[x*model.TileSize+xt][y*model.TileSize+yt] = color.RGBA{uR, uG, uB, uA}
+						output[x*model.TileWidth+xt][y*model.TileHeight+yt] = color.RGBA{uR, uG, uB, uA}
 					}
 				}
 			}
@@ -405,6 +614,25 @@ func (model *SimpleTiledModel) RenderIncompleteImage() image.Image {
 	return GeneratedImage{output}
 }
 
+/**
+ * Retrieve the tile chosen for every slot of the output, as a [x][y] grid
+ * returns: name and number of the tile in each slot, or nil where the slot is undecided or contradicted
+ */
+func (model *SimpleTiledModel) TileGrid() [][]*TileRef {
+	patterns := model.PatternGrid()
+	grid := make([][]*TileRef, len(patterns))
+	for x, column := range patterns {
+		grid[x] = make([]*TileRef, len(column))
+		for y, t := range column {
+			if t != -1 {
+				ref := model.Orientations[t]
+				grid[x][y] = &ref
+			}
+		}
+	}
+	return grid
+}
+
 /**
  * Retrieve the RGBA data
  * returns: Image
@@ -434,3 +662,12 @@ func (model *SimpleTiledModel) Generate() (image.Image, bool) {
 	model.BaseModel.Generate(model)
 	return model.Render(), model.IsGenerationSuccessful()
 }
+
+/**
+ * Retrieve the RGBA data of a new generation from the given seed, without changing the seed of other generations
+ * returns: Image, successful
+ */
+func (model *SimpleTiledModel) GenerateWithSeed(seed int64) (image.Image, bool) {
+	model.BaseModel.GenerateWithSeed(model, seed)
+	return model.Render(), model.IsGenerationSuccessful()
+}
@@ -1,84 +1,26 @@
 package wfc
 
 import (
-	"encoding/json"
+	"bytes"
 	// "fmt"
 	"github.com/shawnridgeway/wfc/internal/testutils"
 	"image"
-	"io/ioutil"
-	"strconv"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"testing/fstest"
 )
 
-// Parsed data supplied by user
-type RawData struct {
-	Path      string        `json:"path"`      // Path to tiles
-	Unique    bool          `json:"unique"`    // Default to false
-	TileSize  int           `json:"tileSize"`  // Default to 16
-	Tiles     []RawTile     `json:"tiles"`     //
-	Neighbors []RawNeighbor `json:"neighbors"` //
-}
-
-// Raw information on a tile
-type RawTile struct {
-	Name     string  `json:"name"`     // Name used to identify the tile
-	Symmetry string  `json:"symmetry"` // Default to ""
-	Weight   float64 `json:"weight"`   // Default to 1
-}
-
-// Information on which tiles can be neighbors
-type RawNeighbor struct {
-	Left     string `json:"left"`     // Mathces Tile.Name
-	LeftNum  int    `json:"leftNum"`  // Default to 0
-	Right    string `json:"right"`    // Mathces Tile.Name
-	RightNum int    `json:"rightNum"` // Default to 0
-}
-
 func initiateData(dataFileName string) SimpleTiledData {
-	// Load data file
-	dataFile, err := ioutil.ReadFile("internal/input/" + dataFileName)
+	data, err := LoadSimpleTiledData(os.DirFS("internal/input"), dataFileName)
 	if err != nil {
 		panic(err)
 	}
-
-	// Parse rawData file
-	var rawData RawData
-	if err := json.Unmarshal(dataFile, &rawData); err != nil {
-		panic(err)
-	}
-
-	// Marshal into data settings struct
-	tiles := make([]Tile, len(rawData.Tiles))
-	for i, rt := range rawData.Tiles {
-		imgs := make([]image.Image, 0)
-		if rawData.Unique {
-			i := 1
-			for {
-				if img, err := testutils.LoadImage("internal/input/" + rawData.Path + rt.Name + " " + strconv.Itoa(i) + ".png"); err == nil {
-					imgs = append(imgs, img)
-				} else {
-					break
-				}
-				i++
-			}
-		} else {
-			img, err := testutils.LoadImage("internal/input/" + rawData.Path + rt.Name + ".png")
-			if err != nil {
-				panic(err)
-			}
-			imgs = append(imgs, img)
-		}
-		weight := rt.Weight
-		if weight == 0 {
-			weight = 1
-		}
-		tiles[i] = Tile{Name: rt.Name, Symmetry: rt.Symmetry, Weight: weight, Variants: imgs}
-	}
-	neighboors := make([]Neighbor, len(rawData.Neighbors))
-	for i, rn := range rawData.Neighbors {
-		neighboors[i] = Neighbor{Left: rn.Left, LeftNum: rn.LeftNum, Right: rn.Right, RightNum: rn.RightNum}
-	}
-	return SimpleTiledData{Unique: rawData.Unique, TileSize: rawData.TileSize, Tiles: tiles, Neighbors: neighboors}
+	return data
 }
 
 func simpleTiledTest(t *testing.T, dataFileName, snapshotFileName string, iterations int) {
@@ -133,3 +75,570 @@ func TestSimpleTiledGenerationCompletes(t *testing.T) {
 func TestSimpleTiledIterationIncomplete(t *testing.T) {
 	simpleTiledTest(t, "castle_data.json", "castle_incomplete.png", 5)
 }
+
+func TestLoadSimpleTiledDataMissingImage(t *testing.T) {
+	fsys := fstest.MapFS{
+		"data.json": &fstest.MapFile{Data: []byte(`{"path": "tiles/", "tileSize": 7, "tiles": [{"name": "ground"}]}`)},
+	}
+	_, err := LoadSimpleTiledData(fsys, "data.json")
+	if err == nil || !strings.Contains(err.Error(), `"ground"`) || !strings.Contains(err.Error(), "tiles/ground.png") {
+		t.Logf("Expected a descriptive error for the missing image, got: %v", err)
+		t.FailNow()
+	}
+}
+
+func TestLoadSimpleTiledDataDefaults(t *testing.T) {
+	data := initiateData("castle_data.json")
+	if data.TileSize != 7 || len(data.Tiles) != 11 || len(data.Neighbors) != 57 {
+		t.Log("Castle tileset was not loaded completely.")
+		t.FailNow()
+	}
+	for _, tile := range data.Tiles {
+		if tile.Weight != 1 || len(tile.Variants) != 1 {
+			t.Logf("Tile %q should default to a weight of 1 and a single variant.", tile.Name)
+			t.FailNow()
+		}
+	}
+}
+
+func TestLoadSimpleTiledDataUnique(t *testing.T) {
+	encoded := func(c color.Color) []byte {
+		img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+		for i := 0; i < 4; i++ {
+			img.Set(i%2, i/2, c)
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			panic(err)
+		}
+		return buf.Bytes()
+	}
+	fsys := fstest.MapFS{
+		"set/data.json":  &fstest.MapFile{Data: []byte(`{"unique": true, "tileSize": 2, "tiles": [{"name": "pipe", "symmetry": "I", "weight": 0.5}]}`)},
+		"set/sized.json": &fstest.MapFile{Data: []byte(`{"unique": true, "tileWidth": 2, "tileHeight": 2, "tiles": [{"name": "pipe", "symmetry": "I"}]}`)},
+		"set/pipe 1.png": &fstest.MapFile{Data: encoded(color.RGBA{255, 0, 0, 255})},
+		"set/pipe 2.png": &fstest.MapFile{Data: encoded(color.RGBA{0, 0, 255, 255})},
+	}
+	data, err := LoadSimpleTiledData(fsys, "set/data.json")
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if len(data.Tiles[0].Variants) != 2 || data.Tiles[0].Weight != 0.5 {
+		t.Log("Unique tile variants were not loaded.")
+		t.FailNow()
+	}
+
+	// The default size does not apply when the width and height are given
+	data, err = LoadSimpleTiledData(fsys, "set/sized.json")
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if width, height := data.tileBounds(); data.TileSize != 0 || width != 2 || height != 2 {
+		t.Logf("Expected 2x2 tiles without a tile size, got %dx%d and a size of %d.", width, height, data.TileSize)
+		t.FailNow()
+	}
+}
+
+func TestSimpleTiledSubset(t *testing.T) {
+	data := initiateData("castle_data.json")
+	data.Subsets = map[string][]string{"Roads": {"ground", "road", "roadturn", "t"}}
+
+	model, err := NewSimpleTiledModelSubset(data, "Roads", 10, 10, false)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	// ground (1) + road (2) + roadturn (4) + t (4)
+	if model.T != 11 {
+		t.Logf("Subset model should have 11 orientations, got %d.", model.T)
+		t.FailNow()
+	}
+	subset, _ := data.Subset("Roads")
+	for _, neighbor := range subset.Neighbors {
+		if neighbor.Left == "bridge" || neighbor.Right == "bridge" || neighbor.Left == "wall" || neighbor.Right == "wall" {
+			t.Log("Neighbors mentioning excluded tiles should be dropped.")
+			t.FailNow()
+		}
+	}
+	model.SetSeed(42)
+	if _, success := model.Generate(); !success {
+		t.Log("Failed to generate image with the subset.")
+		t.FailNow()
+	}
+
+	if _, err := NewSimpleTiledModelSubset(data, "Missing", 10, 10, false); err == nil {
+		t.Log("Unknown subset should be rejected.")
+		t.FailNow()
+	}
+}
+
+// Image of a single color, for synthetic tilesets
+func solidImage(c color.Color, size int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestSimpleTiledConstraintOnly(t *testing.T) {
+	white := color.RGBA{255, 255, 255, 255}
+	black := color.RGBA{0, 0, 0, 255}
+	data := SimpleTiledData{
+		TileSize: 2,
+		Tiles: []Tile{
+			{Name: "white", Symmetry: "X", Variants: []image.Image{solidImage(white, 2)}},
+			{Name: "black", Symmetry: "X", Variants: []image.Image{solidImage(black, 2)}, ConstraintOnly: true},
+		},
+		Neighbors: []Neighbor{
+			{Left: "white", Right: "black"},
+			{Left: "black", Right: "white"},
+		},
+	}
+
+	model := NewSimpleTiledModel(data, 4, 4, false)
+	if model.Stationary[0] != 1 || model.Stationary[1] != 0 {
+		t.Logf("Unset weight should default to 1 and constraint-only to 0, got %v.", model.Stationary)
+		t.FailNow()
+	}
+
+	// Black is never observed, but is forced around every white tile into a checkerboard
+	model.SetSeed(42)
+	outputImg, success := model.Generate()
+	if !success {
+		t.Log("Failed to generate image on the first try.")
+		t.FailNow()
+	}
+	first := outputImg.At(0, 0)
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 4; y++ {
+			expected := first
+			if (x+y)%2 == 1 {
+				expected = outputImg.At(2, 0)
+			}
+			if outputImg.At(x*2, y*2) != expected || first == outputImg.At(2, 0) {
+				t.Log("Output should be a checkerboard of white and black tiles.")
+				t.FailNow()
+			}
+		}
+	}
+
+	// A slot where only constraint-only tiles remain cannot be decided
+	data.Tiles[0].ConstraintOnly = true
+	model = NewSimpleTiledModel(data, 4, 4, false)
+	model.SetSeed(42)
+	if _, success := model.Generate(); success {
+		t.Log("Generation with only constraint-only tiles should not succeed.")
+		t.FailNow()
+	}
+}
+
+func TestSimpleTiledVerticalNeighbors(t *testing.T) {
+	// With rotations, a vertical constraint is a horizontal one rotated a quarter turn
+	data := initiateData("castle_data.json")
+	expected := NewSimpleTiledModel(data, 4, 4, false)
+	orientations := newTileOrientations(data.Tiles, allTileTransforms)
+	quarterTurn := func(name string, num int) int {
+		t, _ := orientations.orientation(name, num)
+		_, rotated := orientations.reference(orientations.action[t][1])
+		return rotated
+	}
+	data.VerticalNeighbors = make([]VerticalNeighbor, len(data.Neighbors))
+	for i, neighbor := range data.Neighbors {
+		data.VerticalNeighbors[i] = VerticalNeighbor{
+			Top:       neighbor.Right,
+			TopNum:    quarterTurn(neighbor.Right, neighbor.RightNum),
+			Bottom:    neighbor.Left,
+			BottomNum: quarterTurn(neighbor.Left, neighbor.LeftNum),
+		}
+	}
+	data.Neighbors = nil
+	model := NewSimpleTiledModel(data, 4, 4, false)
+	if !reflect.DeepEqual(model.Propagator, expected.Propagator) {
+		t.Log("Vertical constraints should be equivalent to the rotated horizontal ones.")
+		t.FailNow()
+	}
+}
+
+func TestSimpleTiledNoRotation(t *testing.T) {
+	sky := color.RGBA{120, 180, 255, 255}
+	ground := color.RGBA{120, 80, 40, 255}
+	data := SimpleTiledData{
+		TileSize:   2,
+		NoRotation: true,
+		Tiles: []Tile{
+			{Name: "sky", Symmetry: "X", Variants: []image.Image{solidImage(sky, 2)}},
+			{Name: "ground", Symmetry: "X", Variants: []image.Image{solidImage(ground, 2)}},
+		},
+		Neighbors: []Neighbor{
+			{Left: "sky", Right: "sky"},
+			{Left: "ground", Right: "ground"},
+		},
+		VerticalNeighbors: []VerticalNeighbor{
+			{Top: "sky", Bottom: "sky"},
+			{Top: "sky", Bottom: "ground"},
+			{Top: "ground", Bottom: "ground"},
+		},
+	}
+
+	model, err := NewSimpleTiledModelChecked(data, 8, 8, false)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	model.SetSeed(42)
+	outputImg, success := model.Generate()
+	if !success {
+		t.Log("Failed to generate image on the first try.")
+		t.FailNow()
+	}
+
+	// Rows are uniform, and ground is never above sky
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if outputImg.At(x*2, y*2) != outputImg.At(0, y*2) {
+				t.Logf("Row %d should be uniform.", y)
+				t.FailNow()
+			}
+		}
+		if y > 0 && outputImg.At(0, (y-1)*2) == ground && outputImg.At(0, y*2) == sky {
+			t.Logf("Ground above sky in row %d.", y)
+			t.FailNow()
+		}
+	}
+
+	data.Tiles[0].Symmetry = "L"
+	data.VerticalNeighbors[0].TopNum = 1
+	if err := data.Validate(); err == nil || !strings.Contains(err.Error(), "never rotated") {
+		t.Logf("Expected rotated references to be rejected, got: %v", err)
+		t.FailNow()
+	}
+}
+
+func TestSimpleTiledDiagonalNeighbors(t *testing.T) {
+	white := color.RGBA{255, 255, 255, 255}
+	black := color.RGBA{0, 0, 0, 255}
+	data := SimpleTiledData{
+		TileSize: 1,
+		Tiles: []Tile{
+			{Name: "white", Symmetry: "X", Variants: []image.Image{solidImage(white, 1)}},
+			{Name: "black", Symmetry: "X", Variants: []image.Image{solidImage(black, 1)}},
+		},
+		Neighbors: []Neighbor{
+			{Left: "white", Right: "white"},
+			{Left: "white", Right: "black"},
+			{Left: "black", Right: "black"},
+		},
+	}
+	if model := NewSimpleTiledModel(data, 4, 4, false); len(model.Propagator) != 4 {
+		t.Logf("Expected 4 directions without diagonal constraints, got %d.", len(model.Propagator))
+		t.FailNow()
+	}
+
+	// Only identical tiles can touch diagonally, in both diagonal directions
+	data.DiagonalNeighbors = []DiagonalNeighbor{
+		{Top: "white", Bottom: "white"},
+		{Top: "black", Bottom: "black"},
+	}
+	model, err := NewSimpleTiledModelChecked(data, 8, 8, false)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	model.SetSeed(42)
+	outputImg, success := model.Generate()
+	if !success {
+		t.Log("Failed to generate image on the first try.")
+		t.FailNow()
+	}
+	for x := 0; x < 7; x++ {
+		for y := 0; y < 7; y++ {
+			if outputImg.At(x, y) != outputImg.At(x+1, y+1) || outputImg.At(x+1, y) != outputImg.At(x, y+1) {
+				t.Logf("Diagonal tiles at (%d, %d) differ.", x, y)
+				t.FailNow()
+			}
+		}
+	}
+}
+
+func TestSimpleTiledNonSquare(t *testing.T) {
+	green := color.RGBA{0, 160, 0, 255}
+	corner := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	draw.Draw(corner, corner.Bounds(), &image.Uniform{green}, image.Point{}, draw.Src)
+	corner.Set(0, 0, color.RGBA{200, 0, 0, 255})
+	data := SimpleTiledData{
+		TileWidth:  4,
+		TileHeight: 2,
+		Tiles: []Tile{
+			{Name: "grass", Symmetry: "X", Variants: []image.Image{solidImage(green, 4)}},
+			{Name: "corner", Symmetry: "F", Variants: []image.Image{corner}},
+		},
+	}
+
+	data, err := InferNeighbors(data, 0)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	model, err := NewSimpleTiledModelChecked(data, 6, 5, false)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if model.T != 5 {
+		t.Logf("Expected only half turns and mirrors of the corner, got %d orientations in total.", model.T)
+		t.FailNow()
+	}
+	model.SetSeed(42)
+	outputImg, success := model.Generate()
+	if !success {
+		t.Log("Failed to generate image on the first try.")
+		t.FailNow()
+	}
+	if bounds := outputImg.Bounds(); bounds.Dx() != 24 || bounds.Dy() != 10 {
+		t.Logf("Expected a 24x10 image, got %v.", bounds)
+		t.FailNow()
+	}
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 24; x++ {
+			if x%4 == 3 && x+1 < 24 && outputImg.At(x, y) != outputImg.At(x+1, y) {
+				t.Logf("Pixels at (%d, %d) and (%d, %d) do not match.", x, y, x+1, y)
+				t.FailNow()
+			}
+			if y%2 == 1 && y+1 < 10 && outputImg.At(x, y) != outputImg.At(x, y+1) {
+				t.Logf("Pixels at (%d, %d) and (%d, %d) do not match.", x, y, x, y+1)
+				t.FailNow()
+			}
+		}
+	}
+}
+
+func TestSimpleTiledAlternates(t *testing.T) {
+	looks := []color.RGBA{{0, 160, 0, 255}, {0, 140, 0, 255}, {20, 160, 20, 255}}
+	data := SimpleTiledData{
+		TileSize: 2,
+		Tiles: []Tile{{
+			Name:       "grass",
+			Symmetry:   "X",
+			Variants:   []image.Image{solidImage(looks[0], 2)},
+			Alternates: [][]image.Image{{solidImage(looks[1], 2)}, {solidImage(looks[2], 2)}},
+		}},
+		Neighbors: []Neighbor{{Left: "grass", Right: "grass"}},
+	}
+	model, err := NewSimpleTiledModelChecked(data, 8, 8, false)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if model.T != 1 {
+		t.Logf("Alternates should not add orientations, got %d.", model.T)
+		t.FailNow()
+	}
+	model.SetSeed(42)
+	outputImg, success := model.Generate()
+	if !success {
+		t.Log("Failed to generate image on the first try.")
+		t.FailNow()
+	}
+
+	counts := make(map[color.Color]int)
+	for x := 0; x < 8; x++ {
+		for y := 0; y < 8; y++ {
+			counts[outputImg.At(x*2, y*2)]++
+		}
+	}
+	for _, look := range looks {
+		if counts[look] == 0 {
+			t.Logf("Expected every look to be used, got %v.", counts)
+			t.FailNow()
+		}
+	}
+
+	// Looks only depend on the seed and the slot
+	if !reflect.DeepEqual(outputImg, model.Render()) {
+		t.Log("Rendering twice should give the same looks.")
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(outputImg, model.RenderIncompleteImage()) {
+		t.Log("Collapsed slots of an incomplete image should have the same looks.")
+		t.FailNow()
+	}
+	model.AlternateSeed = 1
+	if reflect.DeepEqual(outputImg, model.Render()) {
+		t.Log("Another seed should give other looks.")
+		t.FailNow()
+	}
+}
+
+func TestSimpleTiledReskin(t *testing.T) {
+	data := initiateData("castle_data.json")
+	model := NewSimpleTiledModel(data, 10, 10, false)
+	model.SetSeed(42)
+	original, success := model.Generate()
+	if !success {
+		t.Log("Failed to generate image on the first try.")
+		t.FailNow()
+	}
+
+	// Night theme: the same tiles with darker colors, at twice the resolution
+	darken := func(c color.Color) color.Color {
+		r, g, b, a := c.RGBA()
+		return color.RGBA64{uint16(r / 2), uint16(g / 2), uint16(b / 2), uint16(a)}
+	}
+	theme := data
+	theme.TileSize = data.TileSize * 2
+	theme.Tiles = make([]Tile, len(data.Tiles))
+	for i, tile := range data.Tiles {
+		img := image.NewRGBA64(image.Rect(0, 0, theme.TileSize, theme.TileSize))
+		for y := 0; y < theme.TileSize; y++ {
+			for x := 0; x < theme.TileSize; x++ {
+				img.Set(x, y, darken(tile.Variants[0].At(x/2, y/2)))
+			}
+		}
+		tile.Variants = []image.Image{img}
+		theme.Tiles[i] = tile
+	}
+
+	if err := model.Reskin(theme); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	reskinned := model.Render()
+	if bounds := reskinned.Bounds(); bounds.Dx() != 2*original.Bounds().Dx() {
+		t.Logf("Expected the image to be twice as large, got %v.", bounds)
+		t.FailNow()
+	}
+	for y := 0; y < original.Bounds().Dy(); y++ {
+		for x := 0; x < original.Bounds().Dx(); x++ {
+			r1, g1, b1, _ := darken(original.At(x, y)).RGBA()
+			r2, g2, b2, _ := reskinned.At(x*2+1, y*2+1).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 {
+				t.Logf("Pixel at (%d, %d) does not match the theme.", x, y)
+				t.FailNow()
+			}
+		}
+	}
+
+	theme.Tiles = theme.Tiles[1:]
+	if err := model.Reskin(theme); err == nil || !strings.Contains(err.Error(), `tile "bridge" is missing`) {
+		t.Logf("Expected the missing tile to be reported, got: %v", err)
+		t.FailNow()
+	}
+}
+
+func TestSimpleTiledTileGrid(t *testing.T) {
+	data := initiateData("castle_data.json")
+	model := NewSimpleTiledModel(data, 10, 10, false)
+	model.SetSeed(42)
+	if _, success := model.Generate(); !success {
+		t.Log("Failed to generate image on the first try.")
+		t.FailNow()
+	}
+
+	grid := model.TileGrid()
+	patterns := model.PatternGrid()
+	for x, column := range grid {
+		for y, ref := range column {
+			if ref == nil {
+				t.Logf("Slot (%d, %d) is undecided after a successful generation.", x, y)
+				t.FailNow()
+			}
+			expected, ok := newTileOrientations(data.Tiles, data.transforms()).orientation(ref.Name, ref.Num)
+			if !ok || expected != patterns[x][y] {
+				t.Logf("Slot (%d, %d) holds %+v, which is not pattern %d.", x, y, *ref, patterns[x][y])
+				t.FailNow()
+			}
+		}
+	}
+
+	model.Clear()
+	for _, column := range model.TileGrid() {
+		for _, ref := range column {
+			if ref != nil {
+				t.Log("Expected every slot to be undecided after clearing.")
+				t.FailNow()
+			}
+		}
+	}
+}
+
+func TestSimpleTiledSetSize(t *testing.T) {
+	model := NewSimpleTiledModel(initiateData("castle_data.json"), 5, 5, false)
+	model.SetSeed(7)
+	model.Generate()
+	if err := model.SetSize(20, 20); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	model.SetSeed(42)
+	outputImg, success := model.Generate()
+	if !success {
+		t.Log("Failed to generate image on the first try.")
+		t.FailNow()
+	}
+	snapshotImg, err := testutils.LoadImage("internal/snapshots/castle.png")
+	if err != nil {
+		panic(err)
+	}
+	if !testutils.CompareImages(outputImg, snapshotImg) {
+		t.Log("Output image is not the same as the snapshot image.")
+		t.FailNow()
+	}
+
+	if err := model.SetSize(-1, 20); err == nil || model.Fmx != 20 {
+		t.Log("Negative size should be rejected, leaving the model unchanged.")
+		t.FailNow()
+	}
+}
+
+func TestSimpleTiledWaveInspection(t *testing.T) {
+	model := NewSimpleTiledModel(initiateData("castle_data.json"), 10, 10, false)
+	model.SetSeed(42)
+	model.Clear()
+	if model.CollapsedCount() != 0 || len(model.Possibilities(5, 5)) != model.T || model.Entropy(5, 5) <= 0 {
+		t.Log("Expected every tile to be possible in the middle before the first observation.")
+		t.FailNow()
+	}
+	model.Iterate(5)
+	if collapsed := model.CollapsedCount(); collapsed < 5 {
+		t.Logf("Expected at least 5 collapsed slots after 5 iterations, got %d.", collapsed)
+		t.FailNow()
+	}
+	for x := 0; x < model.Fmx; x++ {
+		for y := 0; y < model.Fmy; y++ {
+			if model.IsCollapsed(x, y) != (len(model.Possibilities(x, y)) == 1) {
+				t.Logf("Slot (%d, %d) is inconsistent with IsCollapsed.", x, y)
+				t.FailNow()
+			}
+		}
+	}
+
+	// A slot left with constraint-only tiles has no entropy, but is not collapsed
+	white := color.RGBA{255, 255, 255, 255}
+	black := color.RGBA{0, 0, 0, 255}
+	gray := color.RGBA{128, 128, 128, 255}
+	data := SimpleTiledData{
+		TileSize: 2,
+		Tiles: []Tile{
+			{Name: "white", Symmetry: "X", Variants: []image.Image{solidImage(white, 2)}},
+			{Name: "black", Symmetry: "X", Variants: []image.Image{solidImage(black, 2)}, ConstraintOnly: true},
+			{Name: "gray", Symmetry: "X", Variants: []image.Image{solidImage(gray, 2)}, ConstraintOnly: true},
+		},
+		Neighbors: []Neighbor{
+			{Left: "white", Right: "white"},
+			{Left: "white", Right: "black"},
+			{Left: "white", Right: "gray"},
+		},
+	}
+	model = NewSimpleTiledModel(data, 3, 3, false)
+	model.Clear()
+	model.Wave[1][1][0] = false
+	if model.Entropy(1, 1) != 0 || model.IsCollapsed(1, 1) || !reflect.DeepEqual(model.Possibilities(1, 1), []int{1, 2}) {
+		t.Logf("Expected no entropy and two possibilities, got %v and %v.", model.Entropy(1, 1), model.Possibilities(1, 1))
+		t.FailNow()
+	}
+}
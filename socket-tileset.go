@@ -0,0 +1,122 @@
+package wfc
+
+import (
+	"strings"
+)
+
+/**
+ * Sockets Type. Labels of the four edges of a tile, each read clockwise around the tile.
+ * Two edges fit together when one label is the other read backwards. A label made of several parts
+ * separated by "/" (e.g. "sand/water") describes an asymmetric edge, and fits the reversed label
+ * ("water/sand"). Labels without "/" describe symmetric edges, and fit the identical label.
+ */
+type Sockets struct {
+	Top, Right, Bottom, Left string
+}
+
+// Tile described by the sockets of its edges instead of by neighbor constraints
+type SocketTile struct {
+	Tile            // Tile as drawn, in orientation 0
+	Sockets Sockets // Edges of the tile, in orientation 0
+}
+
+// Tileset where neighbor constraints are derived from edge sockets
+type SocketTiledData struct {
+	Unique     bool                // False if each tile can have variants. (Default to false?)
+	TileSize   int                 // Default to 16
+	TileWidth  int                 // Width in pixels of tiles that are not square. Default to TileSize
+	TileHeight int                 // Height in pixels of tiles that are not square. Default to TileSize
+	Tiles      []SocketTile        // List of all possible tiles, not including inversions
+	Subsets    map[string][]string // Named lists of tile names that can be used instead of the whole tileset
+	// Only use tiles as drawn, never rotated nor mirrored (see SimpleTiledData.NoRotation)
+	NoRotation bool
+}
+
+// Read an edge label in the opposite direction
+func reverseSocket(label string) string {
+	parts := strings.Split(label, "/")
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, "/")
+}
+
+// Check whether two touching edges, each read clockwise around its own tile, fit together
+func socketsFit(a, b string) bool {
+	return a == reverseSocket(b)
+}
+
+// Sockets after applying the k-th transform, as in tileSymmetry.act
+func (sockets Sockets) transform(k int) Sockets {
+	for r := 0; r < k%4; r++ {
+		sockets = Sockets{Top: sockets.Right, Right: sockets.Bottom, Bottom: sockets.Left, Left: sockets.Top}
+	}
+	if k >= 4 {
+		sockets = Sockets{
+			Top:    reverseSocket(sockets.Top),
+			Right:  reverseSocket(sockets.Left),
+			Bottom: reverseSocket(sockets.Bottom),
+			Left:   reverseSocket(sockets.Right),
+		}
+	}
+	return sockets
+}
+
+// Sockets of every orientation of the tileset
+func (data SocketTiledData) orientationSockets(orientations tileOrientations) []Sockets {
+	result := make([]Sockets, len(orientations.tileIndex))
+	for t := range result {
+		i, k := orientations.reference(t)
+		result[t] = data.Tiles[i].Sockets.transform(k)
+	}
+	return result
+}
+
+/**
+ * Convert to a SimpleTiledData, generating the neighbor constraints of every pair of orientations whose edges fit.
+ * returns: the tileset, or a *ValidationError if a tile is invalid or its sockets do not have its declared symmetry
+ */
+func (data SocketTiledData) SimpleTiledData() (SimpleTiledData, error) {
+	result := SimpleTiledData{
+		Unique:     data.Unique,
+		TileSize:   data.TileSize,
+		TileWidth:  data.TileWidth,
+		TileHeight: data.TileHeight,
+		Subsets:    data.Subsets,
+		NoRotation: data.NoRotation,
+	}
+	result.Tiles = make([]Tile, len(data.Tiles))
+	for i, tile := range data.Tiles {
+		result.Tiles[i] = tile.Tile
+	}
+
+	v := &validator{subject: "socket tileset"}
+	result.validate(v)
+	if err := v.result(); err != nil {
+		return SimpleTiledData{}, err
+	}
+
+	// Transforms that leave a tile unchanged must leave its sockets unchanged too
+	orientations := newTileOrientations(result.Tiles, result.transforms())
+	for i, tile := range data.Tiles {
+		symmetry := orientations.symmetries[i]
+		for _, k := range orientations.transforms {
+			if symmetry.act(0, k) == 0 && tile.Sockets.transform(k) != tile.Sockets {
+				v.add("sockets %+v of tile %q (index %d) do not have symmetry %q", tile.Sockets, tile.Name, i, tile.Symmetry)
+				break
+			}
+		}
+	}
+	if err := v.result(); err != nil {
+		return SimpleTiledData{}, err
+	}
+
+	sockets := data.orientationSockets(orientations)
+	result.Neighbors, result.VerticalNeighbors = orientations.neighbors(result.Tiles, func(l, r int) bool {
+		return socketsFit(sockets[l].Right, sockets[r].Left)
+	}, func(u, d int) bool {
+		return socketsFit(sockets[u].Bottom, sockets[d].Top)
+	})
+
+	return result, nil
+}
@@ -0,0 +1,67 @@
+package wfc
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func coastTileset() SocketTiledData {
+	land := solidImage(color.RGBA{0, 160, 0, 255}, 2)
+	sea := solidImage(color.RGBA{0, 0, 160, 255}, 2)
+	return SocketTiledData{
+		TileSize: 2,
+		Tiles: []SocketTile{
+			{Tile: Tile{Name: "land", Symmetry: "X", Variants: []image.Image{land}}, Sockets: Sockets{"g", "g", "g", "g"}},
+			{Tile: Tile{Name: "sea", Symmetry: "X", Variants: []image.Image{sea}}, Sockets: Sockets{"w", "w", "w", "w"}},
+			{Tile: Tile{Name: "coast", Symmetry: "T", Variants: []image.Image{land}}, Sockets: Sockets{"g", "g/w", "w", "w/g"}},
+			{Tile: Tile{Name: "cove", Symmetry: "L", Variants: []image.Image{sea}}, Sockets: Sockets{"w/g", "g/w", "w", "w"}},
+		},
+	}
+}
+
+func TestSocketTileset(t *testing.T) {
+	sockets := coastTileset()
+	data, err := sockets.SimpleTiledData()
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	model := NewSimpleTiledModel(data, 12, 12, false)
+	model.SetSeed(42)
+	if _, success := model.Generate(); !success {
+		t.Log("Failed to generate image on the first try.")
+		t.FailNow()
+	}
+
+	// Every pair of touching edges in the output fits
+	orientations := sockets.orientationSockets(newTileOrientations(data.Tiles, allTileTransforms))
+	chosen := func(x, y int) Sockets {
+		for t, allowed := range model.Wave[x][y] {
+			if allowed {
+				return orientations[t]
+			}
+		}
+		return Sockets{}
+	}
+	for x := 0; x < 12; x++ {
+		for y := 0; y < 12; y++ {
+			if x < 11 && !socketsFit(chosen(x, y).Right, chosen(x+1, y).Left) {
+				t.Logf("Edges at (%d, %d) and (%d, %d) do not fit.", x, y, x+1, y)
+				t.FailNow()
+			}
+			if y < 11 && !socketsFit(chosen(x, y).Bottom, chosen(x, y+1).Top) {
+				t.Logf("Edges at (%d, %d) and (%d, %d) do not fit.", x, y, x, y+1)
+				t.FailNow()
+			}
+		}
+	}
+
+	// Sockets must agree with the declared symmetry
+	sockets.Tiles[2].Symmetry = "I"
+	if _, err := sockets.SimpleTiledData(); err == nil {
+		t.Log("Sockets without the declared symmetry should be rejected.")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,154 @@
+package wfc
+
+import (
+	"image"
+	"strconv"
+)
+
+// Identify the pixels of a tile, to find identical tiles
+func patternKey(pattern TilePattern) string {
+	key := make([]byte, 0, len(pattern)*8)
+	for _, c := range pattern {
+		r, g, b, a := c.RGBA()
+		key = append(key, byte(r>>8), byte(r), byte(g>>8), byte(g), byte(b>>8), byte(b), byte(a>>8), byte(a))
+	}
+	return string(key)
+}
+
+// Check whether every pixel of a pattern is fully transparent
+func isTransparent(pattern TilePattern) bool {
+	for _, c := range pattern {
+		if _, _, _, a := c.RGBA(); a != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+ * SliceSpriteSheet
+ * Cut a sprite sheet into tiles. Tiles that are identical up to rotation and reflection are only kept once,
+ * in the orientation with the most symmetries (see DetectSymmetry), and fully transparent cells are skipped.
+ * Tiles are named "tile0", "tile1", etc. in the order they appear, row by row.
+ * The result has no neighbor constraints: use InferNeighbors or LearnSimpleTiledDataFromImage to add them.
+ * @param {image.Image} sheet The sprite sheet
+ * @param {int} tileSize The width and height of each tile in pixels
+ * @param {int} margin Pixels around the tiles, at the edges of the sheet
+ * @param {int} spacing Pixels between two tiles
+ * @return SimpleTiledData The tiles
+ * @return error A *ValidationError if the arguments are invalid or the sheet has no tiles
+ */
+func SliceSpriteSheet(sheet image.Image, tileSize, margin, spacing int) (SimpleTiledData, error) {
+	v := &validator{subject: "sprite sheet"}
+	if tileSize <= 0 {
+		v.add("tile size must be positive, got %d", tileSize)
+	}
+	if margin < 0 || spacing < 0 {
+		v.add("margin and spacing must not be negative, got %d and %d", margin, spacing)
+	}
+	if sheet == nil {
+		v.add("sprite sheet is nil")
+	}
+	if err := v.result(); err != nil {
+		return SimpleTiledData{}, err
+	}
+
+	bounds := sheet.Bounds()
+	columns := (bounds.Dx() - 2*margin + spacing) / (tileSize + spacing)
+	rows := (bounds.Dy() - 2*margin + spacing) / (tileSize + spacing)
+
+	data := SimpleTiledData{TileSize: tileSize, Tiles: make([]Tile, 0)}
+	known := make(map[string]bool)
+	for row := 0; row < rows; row++ {
+		for column := 0; column < columns; column++ {
+			left := bounds.Min.X + margin + column*(tileSize+spacing)
+			top := bounds.Min.Y + margin + row*(tileSize+spacing)
+
+			drawn := cellPattern(sheet, left, top, tileSize, tileSize, 0)
+			if isTransparent(drawn) || known[patternKey(drawn)] {
+				continue
+			}
+
+			// Keep the orientation described by the symmetry class with the fewest orientations
+			var best TilePattern
+			bestSymmetry := ""
+			for k := 0; k < transformCount; k++ {
+				pattern := cellPattern(sheet, left, top, tileSize, tileSize, k)
+				known[patternKey(pattern)] = true
+				symmetry := DetectSymmetry(patternImage(pattern, tileSize, tileSize), tileSize)
+				if best == nil || tileSymmetries[symmetry].cardinality < tileSymmetries[bestSymmetry].cardinality {
+					best = pattern
+					bestSymmetry = symmetry
+				}
+			}
+
+			data.Tiles = append(data.Tiles, Tile{
+				Name:     "tile" + strconv.Itoa(len(data.Tiles)),
+				Symmetry: bestSymmetry,
+				Variants: []image.Image{patternImage(best, tileSize, tileSize)},
+			})
+		}
+	}
+
+	if len(data.Tiles) == 0 {
+		v.add("no tiles in a %dx%d sheet with tile size %d, margin %d and spacing %d", bounds.Dx(), bounds.Dy(), tileSize, margin, spacing)
+		return SimpleTiledData{}, v.result()
+	}
+	return data, nil
+}
+
+/**
+ * LearnSimpleTiledDataFromImage
+ * Same as LearnSimpleTiledData, but reading the example map from an image made of tiles of data,
+ * in any of their orientations, placed side by side without spacing, and filling the whole image.
+ * @param {image.Image} example The example map
+ * @return SimpleTiledData A copy of data with the learned neighbors and weights
+ * @return error A *ValidationError if a tile is invalid, the image is not made of whole tiles, or a cell of the
+ * image does not match any tile
+ */
+func LearnSimpleTiledDataFromImage(data SimpleTiledData, example image.Image, periodic bool) (SimpleTiledData, error) {
+	data.Neighbors = nil
+	data.VerticalNeighbors = nil
+	v := &validator{subject: "example image"}
+	data.validate(v)
+	if example == nil {
+		v.add("example image is nil")
+	}
+	if err := v.result(); err != nil {
+		return SimpleTiledData{}, err
+	}
+
+	// Find tiles by their pixels, preferring the first orientation that has them
+	orientations := newTileOrientations(data.Tiles, data.transforms())
+	refs := make(map[string]TileRef)
+	for t, pattern := range orientationPatterns(data, orientations) {
+		key := patternKey(pattern)
+		if _, ok := refs[key]; !ok {
+			i, num := orientations.reference(t)
+			refs[key] = TileRef{Name: data.Tiles[i].Name, Num: num}
+		}
+	}
+
+	width, height := data.tileBounds()
+	bounds := example.Bounds()
+	if bounds.Dx()%width != 0 || bounds.Dy()%height != 0 {
+		v.add("example image is %dx%d, not a whole number of %dx%d tiles", bounds.Dx(), bounds.Dy(), width, height)
+		return SimpleTiledData{}, v.result()
+	}
+	sample := make([][]TileRef, bounds.Dx()/width)
+	for x := range sample {
+		sample[x] = make([]TileRef, bounds.Dy()/height)
+		for y := range sample[x] {
+			ref, ok := refs[patternKey(cellPattern(example, bounds.Min.X+x*width, bounds.Min.Y+y*height, width, height, 0))]
+			if !ok {
+				v.add("cell (%d, %d) does not match any tile", x, y)
+			}
+			sample[x][y] = ref
+		}
+	}
+	if err := v.result(); err != nil {
+		return SimpleTiledData{}, err
+	}
+
+	return LearnSimpleTiledData(data, sample, periodic)
+}
@@ -0,0 +1,119 @@
+package wfc
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"testing"
+)
+
+func TestSliceSpriteSheet(t *testing.T) {
+	green := color.RGBA{0, 160, 0, 255}
+	blue := color.RGBA{0, 0, 160, 255}
+	shore := image.NewRGBA(image.Rect(0, 0, 3, 3))
+	turned := image.NewRGBA(image.Rect(0, 0, 3, 3))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			shore.Set(x, y, blue)
+			turned.Set(x, y, blue)
+			if x == 0 || y == 0 {
+				shore.Set(x, y, green)
+			}
+			if x == 2 || y == 2 {
+				turned.Set(x, y, green)
+			}
+		}
+	}
+
+	// Three columns and two rows, with a margin of 1 and a spacing of 2
+	sheet := image.NewRGBA(image.Rect(0, 0, 15, 10))
+	cells := []image.Image{solidImage(green, 3), shore, solidImage(blue, 3), turned, nil, solidImage(green, 3)}
+	for i, cell := range cells {
+		if cell != nil {
+			left, top := 1+(i%3)*5, 1+(i/3)*5
+			draw.Draw(sheet, image.Rect(left, top, left+3, top+3), cell, image.Point{}, draw.Src)
+		}
+	}
+
+	data, err := SliceSpriteSheet(sheet, 3, 1, 2)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if len(data.Tiles) != 3 || data.Tiles[0].Symmetry != "X" || data.Tiles[1].Symmetry != "L" || data.Tiles[2].Symmetry != "X" {
+		t.Logf("Expected tiles with symmetries X, L and X, got %+v.", data.Tiles)
+		t.FailNow()
+	}
+
+	if _, err := SliceSpriteSheet(image.NewRGBA(image.Rect(0, 0, 15, 10)), 3, 1, 2); err == nil {
+		t.Log("Expected a transparent sheet to be rejected.")
+		t.FailNow()
+	}
+}
+
+func TestLearnSimpleTiledDataFromImage(t *testing.T) {
+	original := NewSimpleTiledModel(initiateData("castle_data.json"), 12, 12, false)
+	original.SetSeed(42)
+	example, success := original.Generate()
+	if !success {
+		t.Log("Failed to generate image on the first try.")
+		t.FailNow()
+	}
+
+	// The generated image serves both as the sprite sheet and as the example map
+	data, err := SliceSpriteSheet(example, 7, 0, 0)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	data, err = LearnSimpleTiledDataFromImage(data, example, false)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if len(data.Tiles) == 0 || len(data.Neighbors) == 0 {
+		t.Logf("Unexpected learned tileset with %d tiles and %d neighbors.", len(data.Tiles), len(data.Neighbors))
+		t.FailNow()
+	}
+
+	model := NewSimpleTiledModel(data, 12, 12, false)
+	model.SetSeed(42)
+	if _, success := model.Generate(); !success {
+		t.Log("Failed to generate image from the learned tileset on the first try.")
+		t.FailNow()
+	}
+}
+
+func TestSliceSpriteSheetTranslucent(t *testing.T) {
+	// Colors that do not survive premultiplication to 8 bits
+	sheet := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			sheet.Set(x, y, color.NRGBA{200, 100, 50, 128})
+			if x >= 2 && y == 0 {
+				sheet.Set(x, y, color.NRGBA{10, 20, 30, 77})
+			}
+		}
+	}
+
+	data, err := SliceSpriteSheet(sheet, 2, 0, 0)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if _, err := LearnSimpleTiledDataFromImage(data, sheet, false); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+}
+
+func TestLearnSimpleTiledDataFromImagePartialTiles(t *testing.T) {
+	data := initiateData("castle_data.json")
+	example := image.NewRGBA(image.Rect(0, 0, 3*data.TileSize+1, 2*data.TileSize))
+	_, err := LearnSimpleTiledDataFromImage(data, example, false)
+	if err == nil || !strings.Contains(err.Error(), "not a whole number of") {
+		t.Logf("Expected the extra pixels to be reported, got: %v", err)
+		t.FailNow()
+	}
+}
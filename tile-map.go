@@ -0,0 +1,106 @@
+package wfc
+
+// Reference to one orientation of a tile, as in Neighbor
+type TileRef struct {
+	Name string // Matches Tile.Name
+	Num  int    // Default to 0
+}
+
+/**
+ * LearnSimpleTiledData
+ * Learn the neighbor constraints and weights of a tileset from an example map built with its tiles.
+ * Every pair of cells touching horizontally or vertically in the map becomes a neighbor constraint
+ * (a VerticalNeighbor when it cannot be derived from a Neighbor by rotating the tiles),
+ * and the weight of each tile is set so that it is chosen about as often as it appears in the map.
+ * Tiles that do not appear in the map are dropped. The existing constraints of data are ignored.
+ * @param {SimpleTiledData} data The tiles used in the map
+ * @param {[][]TileRef} sample The example map, indexed as sample[x][y]
+ * @param {bool} periodic Whether the map wraps around, so that its opposite edges touch
+ * @return SimpleTiledData A copy of data with the learned neighbors and weights
+ * @return error A *ValidationError if a tile or a cell of the map is invalid
+ */
+func LearnSimpleTiledData(data SimpleTiledData, sample [][]TileRef, periodic bool) (SimpleTiledData, error) {
+	data.Neighbors = nil
+	data.VerticalNeighbors = nil
+	v := &validator{subject: "tile map"}
+	data.validate(v)
+
+	width := len(sample)
+	height := 0
+	if width > 0 {
+		height = len(sample[0])
+	}
+	if width == 0 || height == 0 {
+		v.add("map is empty")
+	}
+	for x, column := range sample {
+		if len(column) != height {
+			v.add("column %d has %d cells, expected %d", x, len(column), height)
+		}
+	}
+	if err := v.result(); err != nil {
+		return SimpleTiledData{}, err
+	}
+
+	orientations := newTileOrientations(data.Tiles, data.transforms())
+	grid := make([][]int, width)
+	for x, column := range sample {
+		grid[x] = make([]int, height)
+		for y, ref := range column {
+			t, ok := orientations.orientation(ref.Name, ref.Num)
+			if _, known := orientations.tileNames[ref.Name]; !known {
+				v.add("cell (%d, %d): unknown tile %q", x, y, ref.Name)
+			} else if ref.Num < 0 || ref.Num >= transformCount {
+				v.add("cell (%d, %d): tile %q has number %d, expected 0 to %d", x, y, ref.Name, ref.Num, transformCount-1)
+			} else if !ok {
+				v.add("cell (%d, %d): tile %q has number %d, but tiles are never rotated nor mirrored", x, y, ref.Name, ref.Num)
+			} else {
+				grid[x][y] = t
+			}
+		}
+	}
+	if err := v.result(); err != nil {
+		return SimpleTiledData{}, err
+	}
+
+	// Count tiles, and record which orientations were seen on the left of and above which
+	count := len(orientations.tileIndex)
+	counts := make([]int, len(data.Tiles))
+	right := make([][]bool, count)
+	below := make([][]bool, count)
+	for t := range right {
+		right[t] = make([]bool, count)
+		below[t] = make([]bool, count)
+	}
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			t := grid[x][y]
+			i, _ := orientations.reference(t)
+			counts[i]++
+
+			if x+1 < width || periodic {
+				right[t][grid[(x+1)%width][y]] = true
+			}
+			if y+1 < height || periodic {
+				below[t][grid[x][(y+1)%height]] = true
+			}
+		}
+	}
+
+	result := data
+	result.Tiles = make([]Tile, 0, len(data.Tiles))
+	for i, tile := range data.Tiles {
+		if counts[i] == 0 {
+			continue
+		}
+		tile.Weight = float64(counts[i]) / float64(len(orientations.symmetries[i].orbit(orientations.transforms)))
+		result.Tiles = append(result.Tiles, tile)
+	}
+	result.Neighbors, result.VerticalNeighbors = orientations.neighbors(data.Tiles, func(a, b int) bool {
+		return right[a][b]
+	}, func(a, b int) bool {
+		return below[a][b]
+	})
+
+	return result, nil
+}
@@ -0,0 +1,98 @@
+package wfc
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLearnSimpleTiledData(t *testing.T) {
+	data := initiateData("castle_data.json")
+	original := NewSimpleTiledModel(data, 12, 12, false)
+	original.SetSeed(42)
+	if _, success := original.Generate(); !success {
+		t.Log("Failed to generate image on the first try.")
+		t.FailNow()
+	}
+
+	// Use the generated map as an example
+	orientations := newTileOrientations(data.Tiles, allTileTransforms)
+	chosen := func(model *SimpleTiledModel, x, y int) int {
+		for t, allowed := range model.Wave[x][y] {
+			if allowed {
+				return t
+			}
+		}
+		return -1
+	}
+	sample := make([][]TileRef, 12)
+	for x := range sample {
+		sample[x] = make([]TileRef, 12)
+		for y := range sample[x] {
+			i, num := orientations.reference(chosen(original, x, y))
+			sample[x][y] = TileRef{Name: data.Tiles[i].Name, Num: num}
+		}
+	}
+
+	learned, err := LearnSimpleTiledData(data, sample, false)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if len(learned.Tiles) == 0 || len(learned.Tiles) > len(data.Tiles) || len(learned.Neighbors) == 0 {
+		t.Logf("Unexpected learned tileset with %d tiles and %d neighbors.", len(learned.Tiles), len(learned.Neighbors))
+		t.FailNow()
+	}
+
+	// The learned constraints only allow pairs that the original tileset allows
+	model := NewSimpleTiledModel(learned, 12, 12, false)
+	model.SetSeed(42)
+	if _, success := model.Generate(); !success {
+		t.Log("Failed to generate image from the learned tileset on the first try.")
+		t.FailNow()
+	}
+	learnedOrientations := newTileOrientations(learned.Tiles, allTileTransforms)
+	originalIndex := func(t int) int {
+		i, num := learnedOrientations.reference(t)
+		original, _ := orientations.orientation(learned.Tiles[i].Name, num)
+		return original
+	}
+	for x := 0; x < 12; x++ {
+		for y := 0; y < 12; y++ {
+			t1 := originalIndex(chosen(model, x, y))
+			if x < 11 && !original.Propagator[0][originalIndex(chosen(model, x+1, y))][t1] {
+				t.Logf("Tiles at (%d, %d) and (%d, %d) are not allowed together.", x, y, x+1, y)
+				t.FailNow()
+			}
+			if y < 11 && !original.Propagator[1][t1][originalIndex(chosen(model, x, y+1))] {
+				t.Logf("Tiles at (%d, %d) and (%d, %d) are not allowed together.", x, y, x, y+1)
+				t.FailNow()
+			}
+		}
+	}
+}
+
+func TestLearnSimpleTiledDataWeights(t *testing.T) {
+	data := initiateData("castle_data.json")
+	sample := [][]TileRef{
+		{{Name: "ground"}, {Name: "ground"}},
+		{{Name: "road", Num: 1}, {Name: "ground"}},
+	}
+	learned, err := LearnSimpleTiledData(data, sample, false)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if len(learned.Tiles) != 2 || learned.Tiles[0].Name != "ground" || learned.Tiles[0].Weight != 3 {
+		t.Logf("Expected only ground with weight 3 and road to be kept, got %+v.", learned.Tiles)
+		t.FailNow()
+	}
+
+	sample[1][1] = TileRef{Name: "lava"}
+	sample[0][1] = TileRef{Name: "ground", Num: 9}
+	_, err = LearnSimpleTiledData(data, sample, false)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Problems) != 2 {
+		t.Logf("Expected 2 problems, got %v.", err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,441 @@
+package wfc
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+/**
+ * Symmetry class of a tile. Orientations are numbered from 0 (the tile as drawn) to cardinality - 1.
+ */
+type tileSymmetry struct {
+	cardinality int       // Number of distinct orientations
+	a           Inversion // Orientation reached by rotating an orientation a quarter turn
+	b           Inversion // Orientation reached by mirroring an orientation horizontally
+}
+
+// Known symmetry classes, by the letter whose shape has the same symmetries
+var tileSymmetries = map[string]tileSymmetry{
+	"L": {
+		cardinality: 4,
+		a:           func(i int) int { return (i + 1) % 4 },
+		b: func(i int) int {
+			if i%2 == 0 {
+				return i + 1
+			}
+			return i - 1
+		},
+	},
+	"T": {
+		cardinality: 4,
+		a:           func(i int) int { return (i + 1) % 4 },
+		b: func(i int) int {
+			if i%2 == 0 {
+				return i
+			}
+			return 4 - i
+		},
+	},
+	"I": {
+		cardinality: 2,
+		a:           func(i int) int { return 1 - i },
+		b:           func(i int) int { return i },
+	},
+	"\\": {
+		cardinality: 2,
+		a:           func(i int) int { return 1 - i },
+		b:           func(i int) int { return 1 - i },
+	},
+	// Half turn symmetry only: 0 and 1 are rotations, 2 and 3 their mirror images
+	"N": {
+		cardinality: 4,
+		a: func(i int) int {
+			if i < 2 {
+				return 1 - i
+			}
+			return 5 - i
+		},
+		b: func(i int) int { return (i + 2) % 4 },
+	},
+	// No symmetry: 0 to 3 are rotations, 4 to 7 their mirror images
+	"F": {
+		cardinality: 8,
+		a: func(i int) int {
+			if i < 4 {
+				return (i + 1) % 4
+			}
+			return 4 + (i-1)%4
+		},
+		b: func(i int) int {
+			if i < 4 {
+				return i + 4
+			}
+			return i - 4
+		},
+	},
+	"X": {
+		cardinality: 1,
+		a:           func(i int) int { return i },
+		b:           func(i int) int { return i },
+	},
+}
+
+// Find the symmetry class of the given name, where "" defaults to "X"
+func lookupSymmetry(name string) (tileSymmetry, bool) {
+	if name == "" {
+		name = "X"
+	}
+	symmetry, ok := tileSymmetries[name]
+	return symmetry, ok
+}
+
+/**
+ * Orientation reached by applying the k-th transform to orientation i.
+ * Transforms 0 to 3 rotate k quarter turns, 4 to 7 rotate k - 4 quarter turns and then mirror.
+ */
+func (symmetry tileSymmetry) act(i, k int) int {
+	for r := 0; r < k%4; r++ {
+		i = symmetry.a(i)
+	}
+	if k >= 4 {
+		i = symmetry.b(i)
+	}
+	return i
+}
+
+// Orientations reachable from orientation 0 using the given transforms, in increasing order
+func (symmetry tileSymmetry) orbit(transforms []int) []int {
+	reached := make([]bool, symmetry.cardinality)
+	for _, k := range transforms {
+		reached[symmetry.act(0, k)] = true
+	}
+	result := make([]int, 0, symmetry.cardinality)
+	for i, ok := range reached {
+		if ok {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// Check whether orientation i is reachable from orientation 0 using the given transforms
+func (symmetry tileSymmetry) reaches(i int, transforms []int) bool {
+	for _, k := range transforms {
+		if symmetry.act(0, k) == i {
+			return true
+		}
+	}
+	return false
+}
+
+// Every transform, for tilesets where tiles can be rotated and mirrored
+var allTileTransforms = []int{0, 1, 2, 3, 4, 5, 6, 7}
+
+// Transforms keeping the width and height of a tile: half turn and mirrors
+var rectangleTransforms = []int{0, 2, 4, 6}
+
+/**
+ * Orientations of every tile of a tileset, numbered in the order of the tiles
+ */
+type tileOrientations struct {
+	transforms []int          // Transforms that can be applied to the tiles, closed under composition
+	action     [][]int        // Orientation reached by applying the k-th transform to orientation t [t][k], or -1 if the transform is not allowed
+	tileNames  map[string]int // Index of each tile, by name
+	symmetries []tileSymmetry // Symmetry class of each tile, by index
+	local      [][]int        // Orientation of each orientation of the symmetry class of each tile [i][local], or -1 if it is not reachable
+	tileIndex  []int          // Index of the tile of each orientation
+	localIndex []int          // Orientation in the symmetry class of the tile of each orientation
+}
+
+// Number the orientations of the tiles reachable with the given transforms. Panics on unknown symmetries, which
+// validation reports as errors.
+func newTileOrientations(tiles []Tile, transforms []int) tileOrientations {
+	orientations := tileOrientations{
+		transforms: transforms,
+		action:     make([][]int, 0),
+		tileNames:  make(map[string]int),
+		symmetries: make([]tileSymmetry, len(tiles)),
+		local:      make([][]int, len(tiles)),
+		tileIndex:  make([]int, 0),
+		localIndex: make([]int, 0),
+	}
+	for i, tile := range tiles {
+		symmetry, ok := lookupSymmetry(tile.Symmetry)
+		if !ok {
+			panic(fmt.Sprintf("wfc: tile %q (index %d) has unknown symmetry %q", tile.Name, i, tile.Symmetry))
+		}
+		orientations.tileNames[tile.Name] = i
+		orientations.symmetries[i] = symmetry
+		orientations.local[i] = make([]int, symmetry.cardinality)
+		for local := range orientations.local[i] {
+			orientations.local[i][local] = -1
+		}
+		for _, local := range symmetry.orbit(transforms) {
+			orientations.local[i][local] = len(orientations.tileIndex)
+			orientations.tileIndex = append(orientations.tileIndex, i)
+			orientations.localIndex = append(orientations.localIndex, local)
+		}
+	}
+
+	for t, i := range orientations.tileIndex {
+		transformed := make([]int, transformCount)
+		for k := range transformed {
+			transformed[k] = -1
+		}
+		for _, k := range transforms {
+			transformed[k] = orientations.local[i][orientations.symmetries[i].act(orientations.localIndex[t], k)]
+		}
+		orientations.action = append(orientations.action, transformed)
+	}
+	return orientations
+}
+
+// Orientation referred to by a tile name and number, as in Neighbor.LeftNum
+func (orientations tileOrientations) orientation(name string, num int) (int, bool) {
+	i, ok := orientations.tileNames[name]
+	if !ok || num < 0 || num >= transformCount {
+		return 0, false
+	}
+	t := orientations.local[i][orientations.symmetries[i].act(0, num)]
+	return t, t >= 0
+}
+
+// First allowed transform turning the tile of orientation t as drawn into orientation t
+func (orientations tileOrientations) transform(t int) int {
+	symmetry := orientations.symmetries[orientations.tileIndex[t]]
+	for _, k := range orientations.transforms {
+		if symmetry.act(0, k) == orientations.localIndex[t] {
+			return k
+		}
+	}
+	return 0
+}
+
+// Tile index and number referring to orientation t, as in Neighbor.LeftNum
+func (orientations tileOrientations) reference(t int) (int, int) {
+	return orientations.tileIndex[t], orientations.transform(t)
+}
+
+// Constraint that orientation b can be placed in the given direction (see tileDirections) of orientation a
+type tileRule struct {
+	a, b, direction int
+}
+
+/**
+ * Apply every allowed transform to a rule, calling apply on each resulting rule and on its reverse.
+ */
+func (orientations tileOrientations) expand(rule tileRule, apply func(tileRule)) {
+	for _, k := range orientations.transforms {
+		a := orientations.action[rule.a][k]
+		b := orientations.action[rule.b][k]
+		direction := transformDirection(rule.direction, k)
+		apply(tileRule{a, b, direction})
+		apply(tileRule{b, a, oppositeDirection(direction)})
+	}
+}
+
+/**
+ * List constraints for every pair of orientations where b fits on the right of a, or below a.
+ * Each pair is emitted once, skipping the pairs the model derives from it by applying the allowed transforms.
+ */
+func (orientations tileOrientations) neighbors(tiles []Tile, right, below func(a, b int) bool) ([]Neighbor, []VerticalNeighbor) {
+	count := len(orientations.action)
+	covered := make([][][]bool, len(tileDirections))
+	for d := range covered {
+		covered[d] = make([][]bool, count)
+		for t := range covered[d] {
+			covered[d][t] = make([]bool, count)
+		}
+	}
+
+	neighbors := make([]Neighbor, 0)
+	verticalNeighbors := make([]VerticalNeighbor, 0)
+	for _, direction := range []int{2, 1} {
+		fits := right
+		if direction == 1 {
+			fits = below
+		}
+		for a := 0; a < count; a++ {
+			for b := 0; b < count; b++ {
+				if covered[direction][a][b] || !fits(a, b) {
+					continue
+				}
+				orientations.expand(tileRule{a, b, direction}, func(rule tileRule) {
+					covered[rule.direction][rule.a][rule.b] = true
+				})
+
+				first, firstNum := orientations.reference(a)
+				second, secondNum := orientations.reference(b)
+				if direction == 2 {
+					neighbors = append(neighbors, Neighbor{
+						Left:     tiles[first].Name,
+						LeftNum:  firstNum,
+						Right:    tiles[second].Name,
+						RightNum: secondNum,
+					})
+				} else {
+					verticalNeighbors = append(verticalNeighbors, VerticalNeighbor{
+						Top:       tiles[first].Name,
+						TopNum:    firstNum,
+						Bottom:    tiles[second].Name,
+						BottomNum: secondNum,
+					})
+				}
+			}
+		}
+	}
+	return neighbors, verticalNeighbors
+}
+
+// Symmetry classes from the most to the least symmetric
+var symmetryOrder = []string{"X", "I", "\\", "T", "L", "N", "F"}
+
+/**
+ * Source pixel of (x, y) after applying the k-th transform to a width by height tile.
+ * Quarter turns (odd k%4) require a square tile.
+ */
+func transformSource(k, x, y, width, height int) (int, int) {
+	if k >= 4 {
+		x = width - 1 - x
+	}
+	if k%4 == 2 {
+		return width - 1 - x, height - 1 - y
+	}
+	for r := 0; r < k%4; r++ {
+		x, y = width-1-y, x
+	}
+	return x, y
+}
+
+// Pixels of the width by height cell of img starting at (left, top), after applying the k-th transform
+func cellPattern(img image.Image, left, top, width, height, k int) TilePattern {
+	result := make(TilePattern, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			sx, sy := transformSource(k, x, y, width, height)
+			result[x+y*width] = img.At(left+sx, top+sy)
+		}
+	}
+	return result
+}
+
+// Copy a width by height pattern to a new image, keeping the exact value of every color
+func patternImage(pattern TilePattern, width, height int) image.Image {
+	bounds := image.Rect(0, 0, width, height)
+	// Use the color model of the pattern when it has one, and otherwise 16 bits per channel, which hold any color.RGBA
+	allRGBA, allNRGBA := true, true
+	for _, c := range pattern {
+		_, isRGBA := c.(color.RGBA)
+		_, isNRGBA := c.(color.NRGBA)
+		allRGBA, allNRGBA = allRGBA && isRGBA, allNRGBA && isNRGBA
+	}
+	var result draw.Image = image.NewRGBA64(bounds)
+	if allRGBA {
+		result = image.NewRGBA(bounds)
+	} else if allNRGBA {
+		result = image.NewNRGBA(bounds)
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			result.Set(x, y, pattern[x+y*width])
+		}
+	}
+	return result
+}
+
+// Check whether the k-th transform leaves the size by size tile unchanged
+func invariantUnder(img image.Image, size, k int) bool {
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			sx, sy := transformSource(k, x, y, size, size)
+			r1, g1, b1, a1 := img.At(x, y).RGBA()
+			r2, g2, b2, a2 := img.At(sx, sy).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Check whether every transform that leaves orientation 0 of the class unchanged also leaves the pixels unchanged
+func (symmetry tileSymmetry) fits(invariant []bool) bool {
+	for k := 0; k < transformCount; k++ {
+		if symmetry.act(0, k) == 0 && !invariant[k] {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+ * DetectSymmetry
+ * Find the symmetry class with the fewest orientations that correctly describes the tile.
+ * Classes assume a specific orientation of the tile as drawn (e.g. "T" must be symmetric left to right),
+ * so a tile drawn in another orientation may be detected as a less symmetric class, down to "F".
+ * @param {image.Image} img The tile, whose top left size by size pixels are inspected
+ * @param {int} size The width and height of the tile in pixels
+ * @return string The symmetry class, to be used as Tile.Symmetry
+ */
+func DetectSymmetry(img image.Image, size int) string {
+	invariant := make([]bool, transformCount)
+	for k := 0; k < transformCount; k++ {
+		invariant[k] = invariantUnder(img, size, k)
+	}
+	for _, name := range symmetryOrder {
+		if tileSymmetries[name].fits(invariant) {
+			return name
+		}
+	}
+	return "F"
+}
+
+// Tile whose declared symmetry does not match its pixels
+type SymmetryMismatch struct {
+	Tile     string // Name of the tile
+	Index    int    // Index of the tile in SimpleTiledData.Tiles
+	Declared string // Symmetry declared in Tile.Symmetry
+	Detected string // Symmetry detected from the pixels of the first variant
+	// True if the declared symmetry is correct but the tile has more symmetries than declared,
+	// which only adds redundant orientations. Otherwise the declared symmetry breaks the output.
+	Compatible bool
+}
+
+/**
+ * Compare the declared symmetry of every tile with the symmetry of its pixels (see DetectSymmetry)
+ * Tiles that are not square are not checked.
+ * returns: every tile whose declared symmetry differs from the detected one
+ */
+func (data SimpleTiledData) LintSymmetry() []SymmetryMismatch {
+	mismatches := make([]SymmetryMismatch, 0)
+	size, height := data.tileBounds()
+	if size != height {
+		return mismatches
+	}
+	for i, tile := range data.Tiles {
+		if len(tile.Variants) == 0 || tile.Variants[0] == nil {
+			continue
+		}
+		declared, ok := lookupSymmetry(tile.Symmetry)
+		detected := DetectSymmetry(tile.Variants[0], size)
+		invariant := make([]bool, transformCount)
+		for k := 0; k < transformCount; k++ {
+			invariant[k] = invariantUnder(tile.Variants[0], size, k)
+		}
+		// Classes of the same cardinality differ by the transforms leaving the tile unchanged, so check those too
+		fits := ok && declared.fits(invariant)
+		if fits && declared.cardinality == tileSymmetries[detected].cardinality {
+			continue
+		}
+		mismatches = append(mismatches, SymmetryMismatch{
+			Tile:       tile.Name,
+			Index:      i,
+			Declared:   tile.Symmetry,
+			Detected:   detected,
+			Compatible: fits && declared.cardinality > tileSymmetries[detected].cardinality,
+		})
+	}
+	return mismatches
+}
@@ -0,0 +1,149 @@
+package wfc
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTileSymmetryActions(t *testing.T) {
+	all := make([]int, transformCount)
+	for k := range all {
+		all[k] = k
+	}
+	for name, symmetry := range tileSymmetries {
+		for i := 0; i < symmetry.cardinality; i++ {
+			a := symmetry.a
+			b := symmetry.b
+			if a(a(a(a(i)))) != i || b(b(i)) != i || b(a(b(i))) != a(a(a(i))) {
+				t.Logf("Symmetry %q does not describe rotations and reflections of a square.", name)
+				t.FailNow()
+			}
+		}
+		if len(symmetry.orbit(all)) != symmetry.cardinality {
+			t.Logf("Symmetry %q has orientations that cannot be reached from orientation 0.", name)
+			t.FailNow()
+		}
+	}
+
+	// Without quarter turns, only the orientations reached by half turns and mirroring are left
+	expected := map[string][]int{
+		"X": {0}, "I": {0}, "\\": {0, 1}, "T": {0, 2}, "N": {0, 2}, "L": {0, 1, 2, 3}, "F": {0, 2, 4, 6},
+	}
+	for name, orientations := range expected {
+		if orbit := tileSymmetries[name].orbit([]int{0, 2, 4, 6}); !reflect.DeepEqual(orbit, orientations) {
+			t.Logf("Symmetry %q should reach %v without quarter turns, got %v.", name, orientations, orbit)
+			t.FailNow()
+		}
+	}
+}
+
+// 3x3 image with the given pixels set
+func pixelImage(pixels ...image.Point) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 3))
+	for x := 0; x < 3; x++ {
+		for y := 0; y < 3; y++ {
+			img.Set(x, y, color.RGBA{0, 0, 0, 255})
+		}
+	}
+	for _, p := range pixels {
+		img.Set(p.X, p.Y, color.RGBA{255, 255, 255, 255})
+	}
+	return img
+}
+
+func TestAdditionalTileSymmetries(t *testing.T) {
+	data := SimpleTiledData{
+		TileSize: 3,
+		Tiles: []Tile{
+			{Name: "f", Symmetry: "F", Variants: []image.Image{pixelImage(image.Pt(0, 0), image.Pt(1, 0), image.Pt(2, 0), image.Pt(0, 1))}},
+			{Name: "n", Symmetry: "N", Variants: []image.Image{pixelImage(image.Pt(0, 0), image.Pt(1, 0), image.Pt(1, 2), image.Pt(2, 2))}},
+		},
+		Neighbors: []Neighbor{{Left: "f", Right: "n"}},
+	}
+	model, err := NewSimpleTiledModelChecked(data, 4, 4, false)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if model.T != 12 {
+		t.Logf("Expected 8 orientations for F and 4 for N, got %d in total.", model.T)
+		t.FailNow()
+	}
+	seen := make(map[string]bool)
+	for _, tile := range model.Tiles {
+		key := ""
+		for _, c := range tile {
+			r, _, _, _ := c.RGBA()
+			key += string(rune('0' + r/0xffff))
+		}
+		if seen[key] {
+			t.Log("Orientations of asymmetric tiles should all be distinct.")
+			t.FailNow()
+		}
+		seen[key] = true
+	}
+
+	data.Tiles[1].Symmetry = "Z"
+	if _, err := NewSimpleTiledModelChecked(data, 4, 4, false); err == nil || !strings.Contains(err.Error(), `unknown symmetry "Z"`) {
+		t.Logf("Expected unknown symmetry to be reported, got: %v", err)
+		t.FailNow()
+	}
+
+	// The unchecked constructor must not silently fall back to another symmetry
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), `unknown symmetry "Z"`) {
+			t.Logf("Expected a panic on unknown symmetry, got: %v", r)
+			t.Fail()
+		}
+	}()
+	NewSimpleTiledModel(data, 4, 4, false)
+}
+
+func TestDetectSymmetry(t *testing.T) {
+	data := initiateData("castle_data.json")
+	for _, tile := range data.Tiles {
+		detected := DetectSymmetry(tile.Variants[0], data.TileSize)
+		// The tower is drawn with more symmetries than declared
+		if detected != tile.Symmetry && tile.Name != "tower" {
+			t.Logf("Tile %q is declared %q but detected as %q.", tile.Name, tile.Symmetry, detected)
+			t.FailNow()
+		}
+	}
+	if DetectSymmetry(pixelImage(image.Pt(0, 0), image.Pt(1, 0), image.Pt(2, 0), image.Pt(0, 1)), 3) != "F" {
+		t.Log("Asymmetric tile should be detected as F.")
+		t.FailNow()
+	}
+
+	mismatches := data.LintSymmetry()
+	if len(mismatches) != 1 || mismatches[0].Tile != "tower" || !mismatches[0].Compatible {
+		t.Logf("Only the tower should be reported as compatible, got %+v.", mismatches)
+		t.FailNow()
+	}
+
+	// A corner declared as a straight piece breaks the output
+	data.Tiles[3].Symmetry = "I"
+	mismatches = data.LintSymmetry()
+	if len(mismatches) != 2 || mismatches[0].Tile != "riverturn" || mismatches[0].Detected != "L" || mismatches[0].Compatible {
+		t.Logf("Riverturn should be reported as incompatible, got %+v.", mismatches)
+		t.FailNow()
+	}
+	// A corner declared as a T has as many orientations, but other symmetries
+	data.Tiles[3].Symmetry = "T"
+	mismatches = data.LintSymmetry()
+	if len(mismatches) != 2 || mismatches[0].Tile != "riverturn" || mismatches[0].Detected != "L" || mismatches[0].Compatible {
+		t.Logf("Riverturn declared as T should be reported as incompatible, got %+v.", mismatches)
+		t.FailNow()
+	}
+
+	// Tiles sized by their width and height only are checked at that size
+	data.Tiles[3].Symmetry = "L"
+	data.TileSize, data.TileWidth, data.TileHeight = 0, 7, 7
+	mismatches = data.LintSymmetry()
+	if len(mismatches) != 1 || mismatches[0].Tile != "tower" {
+		t.Logf("Only the tower should be reported with a tile width and height, got %+v.", mismatches)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,64 @@
+package wfc
+
+/**
+ * Transforms Type. Set of reflections and rotations applied to the patterns extracted from a sample.
+ * Rotations are counter-clockwise, FlipX mirrors horizontally (left becomes right) and FlipY vertically.
+ */
+type Transforms uint8
+
+// Individual transforms, in the order the symmetry shorthand enables them
+const (
+	Identity      Transforms = 1 << iota // Pattern as found in the sample
+	FlipX                                // Horizontal mirror
+	Rotate90                             // Quarter turn
+	AntiTranspose                        // Mirror along the anti-diagonal
+	Rotate180                            // Half turn
+	FlipY                                // Vertical mirror
+	Rotate270                            // Three quarter turn
+	Transpose                            // Mirror along the main diagonal
+)
+
+// Common sets of transforms
+const (
+	NoTransforms  Transforms = Identity
+	Rotations     Transforms = Identity | Rotate90 | Rotate180 | Rotate270
+	Flips         Transforms = Identity | FlipX | FlipY | Rotate180
+	AllTransforms Transforms = 1<<transformCount - 1
+)
+
+const transformCount = 8 // Number of elements in the dihedral group of the square
+
+/**
+ * Convert the symmetry shorthand (1 through 8) into the set made of the first `symmetry` transforms
+ */
+func SymmetryTransforms(symmetry int) Transforms {
+	result := Transforms(0)
+	for k := 0; k < symmetry && k < transformCount; k++ {
+		result |= 1 << k
+	}
+	return result
+}
+
+/**
+ * Check whether the k-th transform (in shorthand order) is part of the set
+ */
+func (transforms Transforms) Has(k int) bool {
+	return transforms&(1<<k) != 0
+}
+
+/**
+ * Validate that the set can be used to extract patterns
+ */
+func (transforms Transforms) Validate() error {
+	v := &validator{subject: "transform set"}
+	transforms.validate(v)
+	return v.result()
+}
+
+func (transforms Transforms) validate(v *validator) {
+	if transforms == 0 {
+		v.add("no transforms")
+	} else if !transforms.Has(0) {
+		v.add("%08b does not include Identity", uint8(transforms))
+	}
+}
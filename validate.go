@@ -0,0 +1,258 @@
+package wfc
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+)
+
+/**
+ * ValidationError Type. Lists every problem found in the input of a constructor.
+ */
+type ValidationError struct {
+	Subject  string  // What was validated, e.g. "tileset"
+	Problems []error // Every problem found, in input order
+}
+
+func (err *ValidationError) Error() string {
+	messages := make([]string, len(err.Problems))
+	for i, problem := range err.Problems {
+		messages[i] = problem.Error()
+	}
+	return fmt.Sprintf("wfc: invalid %s: %s", err.Subject, strings.Join(messages, "; "))
+}
+
+func (err *ValidationError) Unwrap() []error {
+	return err.Problems
+}
+
+// Collect problems, returning nil from result() if there are none
+type validator struct {
+	subject  string
+	problems []error
+}
+
+func (v *validator) add(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Errorf(format, args...))
+}
+
+func (v *validator) result() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Subject: v.subject, Problems: v.problems}
+}
+
+/**
+ * Validate the tiles and constraints, reporting every problem found
+ */
+func (data SimpleTiledData) Validate() error {
+	v := &validator{subject: "tileset"}
+	data.validate(v)
+	return v.result()
+}
+
+func (data SimpleTiledData) validate(v *validator) {
+	width, height := data.tileBounds()
+	if width <= 0 || height <= 0 {
+		v.add("tile size must be positive, got %dx%d", width, height)
+	}
+	if len(data.Tiles) == 0 && len(data.BigTiles) == 0 {
+		v.add("no tiles")
+	}
+
+	symmetries := make(map[string]tileSymmetry)
+	for i, tile := range data.Tiles {
+		if tile.Name == "" {
+			v.add("tile %d has no name", i)
+		} else if _, ok := symmetries[tile.Name]; ok {
+			v.add("tile %q (index %d) is defined more than once", tile.Name, i)
+		}
+		symmetry, ok := lookupSymmetry(tile.Symmetry)
+		if !ok {
+			v.add("tile %q (index %d) has unknown symmetry %q", tile.Name, i, tile.Symmetry)
+		}
+		cardinality := symmetry.cardinality
+		// Orientations that are never reached need no variant
+		reachable := cardinality
+		if ok {
+			reachable = len(symmetry.orbit(data.transforms()))
+		}
+		symmetries[tile.Name] = symmetry
+		checkCount := func(kind string, count int) {
+			if !ok || !data.Unique || count == cardinality || count == reachable {
+				return
+			}
+			if reachable == cardinality {
+				v.add("tile %q (index %d) %s %d variants, symmetry %q of a unique tileset requires %d", tile.Name, i, kind, count, tile.Symmetry, cardinality)
+			} else {
+				v.add("tile %q (index %d) %s %d variants, symmetry %q of a unique tileset requires %d, or %d for the orientations in use", tile.Name, i, kind, count, tile.Symmetry, cardinality, reachable)
+			}
+		}
+
+		if tile.Weight < 0 || math.IsNaN(tile.Weight) || math.IsInf(tile.Weight, 0) {
+			v.add("tile %q (index %d) has invalid weight %v", tile.Name, i, tile.Weight)
+		}
+
+		if len(tile.Variants) == 0 {
+			v.add("tile %q (index %d) has no variants", tile.Name, i)
+		} else {
+			checkCount("has", len(tile.Variants))
+		}
+		checkImages := func(kind string, images []image.Image) {
+			for k, variant := range images {
+				if variant == nil {
+					v.add("tile %q (index %d) %s %d is nil", tile.Name, i, kind, k)
+				} else if bounds := variant.Bounds(); bounds.Min.X != 0 || bounds.Min.Y != 0 {
+					v.add("tile %q (index %d) %s %d bounds must start at (0, 0), got %v", tile.Name, i, kind, k, bounds.Min)
+				} else if bounds.Dx() < width || bounds.Dy() < height {
+					v.add("tile %q (index %d) %s %d is %dx%d, smaller than the tile size %dx%d", tile.Name, i, kind, k, bounds.Dx(), bounds.Dy(), width, height)
+				}
+			}
+		}
+		checkImages("variant", tile.Variants)
+		for j, alternate := range tile.Alternates {
+			if len(alternate) == 0 {
+				v.add("tile %q (index %d) alternate %d has no variants", tile.Name, i, j)
+			} else {
+				checkCount(fmt.Sprintf("alternate %d has", j), len(alternate))
+			}
+			checkImages(fmt.Sprintf("alternate %d variant", j), alternate)
+		}
+	}
+
+	for i, tile := range data.BigTiles {
+		if tile.Name == "" {
+			v.add("big tile %d has no name", i)
+		} else if _, ok := symmetries[tile.Name]; ok {
+			v.add("big tile %q (index %d) is defined more than once", tile.Name, i)
+		}
+		symmetries[tile.Name] = tileSymmetries["F"]
+
+		if tile.Width < 1 || tile.Height < 1 {
+			v.add("big tile %q (index %d) size must be positive, got %dx%d", tile.Name, i, tile.Width, tile.Height)
+		}
+		if tile.Weight < 0 || math.IsNaN(tile.Weight) || math.IsInf(tile.Weight, 0) {
+			v.add("big tile %q (index %d) has invalid weight %v", tile.Name, i, tile.Weight)
+		}
+		if tile.Image == nil {
+			v.add("big tile %q (index %d) image is nil", tile.Name, i)
+		} else if bounds := tile.Image.Bounds(); bounds.Min.X != 0 || bounds.Min.Y != 0 {
+			v.add("big tile %q (index %d) image bounds must start at (0, 0), got %v", tile.Name, i, bounds.Min)
+		} else if bounds.Dx() < tile.Width*width || bounds.Dy() < tile.Height*height {
+			v.add("big tile %q (index %d) image is %dx%d, smaller than %dx%d tiles of size %dx%d", tile.Name, i, bounds.Dx(), bounds.Dy(), tile.Width, tile.Height, width, height)
+		}
+	}
+
+	checkReference := func(rule string, side, name string, num int) {
+		symmetry, ok := symmetries[name]
+		if !ok {
+			v.add("%s: unknown %s tile %q", rule, side, name)
+		} else if num < 0 || num >= transformCount {
+			v.add("%s: %s tile %q has number %d, expected 0 to %d", rule, side, name, num, transformCount-1)
+		} else if !symmetry.reaches(symmetry.act(0, num), data.transforms()) {
+			v.add("%s: %s tile %q has number %d, but tiles are never rotated nor mirrored", rule, side, name, num)
+		}
+	}
+	for i, neighbor := range data.Neighbors {
+		rule := fmt.Sprintf("neighbor %d", i)
+		checkReference(rule, "left", neighbor.Left, neighbor.LeftNum)
+		checkReference(rule, "right", neighbor.Right, neighbor.RightNum)
+	}
+	for i, neighbor := range data.VerticalNeighbors {
+		rule := fmt.Sprintf("vertical neighbor %d", i)
+		checkReference(rule, "top", neighbor.Top, neighbor.TopNum)
+		checkReference(rule, "bottom", neighbor.Bottom, neighbor.BottomNum)
+	}
+	for i, neighbor := range data.DiagonalNeighbors {
+		rule := fmt.Sprintf("diagonal neighbor %d", i)
+		checkReference(rule, "top", neighbor.Top, neighbor.TopNum)
+		checkReference(rule, "bottom", neighbor.Bottom, neighbor.BottomNum)
+	}
+}
+
+// Check the size of a generation
+func validateOutputSize(v *validator, width, height int) {
+	if width <= 0 || height <= 0 {
+		v.add("output size must be positive, got %dx%d", width, height)
+	}
+}
+
+// Check the arguments of an overlapping model
+func validateOverlapping(v *validator, img image.Image, n, width, height int, periodicInput, periodicOutput bool, transforms Transforms) {
+	if n < 1 {
+		v.add("pattern size N must be at least 1, got %d", n)
+	}
+	validateOutputSize(v, width, height)
+	if n >= 1 && !periodicOutput && width > 0 && height > 0 && (width < n || height < n) {
+		v.add("output size %dx%d is smaller than the pattern size %d", width, height, n)
+	}
+	transforms.validate(v)
+
+	if img == nil {
+		v.add("sample image is nil")
+		return
+	}
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		v.add("sample image is empty")
+		return
+	}
+	if bounds.Min.X != 0 || bounds.Min.Y != 0 {
+		v.add("sample image bounds must start at (0, 0), got %v", bounds.Min)
+	}
+	if n >= 1 && !periodicInput && (bounds.Dx() < n || bounds.Dy() < n) {
+		v.add("sample image %dx%d is smaller than the pattern size %d", bounds.Dx(), bounds.Dy(), n)
+	}
+
+	// Patterns are indexed by an integer made of N*N digits in base "number of colors"
+	if n >= 1 {
+		colors := make(map[color.Color]bool)
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				colors[img.At(x, y)] = true
+			}
+		}
+		if math.Pow(float64(len(colors)), float64(n*n)) > math.MaxInt64/2 {
+			v.add("sample image has %d colors, too many for patterns of size %d", len(colors), n)
+		}
+	}
+}
+
+/**
+ * NewSimpleTiledModelChecked
+ * Same as NewSimpleTiledModel, but validating all of the input first.
+ * @return *SimpleTiledModel A pointer to a new copy of the model
+ * @return error A *ValidationError listing every problem, or nil
+ */
+func NewSimpleTiledModelChecked(data SimpleTiledData, width, height int, periodic bool) (*SimpleTiledModel, error) {
+	v := &validator{subject: "tileset"}
+	data.validate(v)
+	validateOutputSize(v, width, height)
+	if err := v.result(); err != nil {
+		return nil, err
+	}
+	return NewSimpleTiledModel(data, width, height, periodic), nil
+}
+
+/**
+ * NewOverlappingModelChecked
+ * Same as NewOverlappingModel, but validating all of the input first.
+ * @return *OverlappingModel A pointer to a new copy of the model
+ * @return error A *ValidationError listing every problem, or nil
+ */
+func NewOverlappingModelChecked(img image.Image, n, width, height int, periodicInput, periodicOutput bool, symmetry int, ground bool) (*OverlappingModel, error) {
+	v := &validator{subject: "overlapping model"}
+	transforms := SymmetryTransforms(symmetry)
+	if symmetry < 1 || symmetry > transformCount {
+		v.add("symmetry must be between 1 and %d, got %d", transformCount, symmetry)
+		transforms = Identity
+	}
+	validateOverlapping(v, img, n, width, height, periodicInput, periodicOutput, transforms)
+	if err := v.result(); err != nil {
+		return nil, err
+	}
+	return newOverlappingModel(img, n, width, height, periodicInput, periodicOutput, transforms, ground), nil
+}
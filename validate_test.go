@@ -0,0 +1,84 @@
+package wfc
+
+import (
+	"errors"
+	"github.com/shawnridgeway/wfc/internal/testutils"
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestSimpleTiledValidation(t *testing.T) {
+	data := initiateData("castle_data.json")
+	if _, err := NewSimpleTiledModelChecked(data, 20, 20, false); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	data.TileSize = 0
+	data.Tiles = append([]Tile{}, data.Tiles...)
+	data.Tiles[1].Variants = nil
+	data.Neighbors = append([]Neighbor{}, data.Neighbors...)
+	data.Neighbors[0].Left = "bridgee"
+	data.Neighbors[1].RightNum = 9
+	_, err := NewSimpleTiledModelChecked(data, 0, 20, false)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Logf("Expected a validation error, got: %v", err)
+		t.FailNow()
+	}
+	for _, expected := range []string{"tile size", `tile "ground" (index 1) has no variants`, `neighbor 0: unknown left tile "bridgee"`, `neighbor 1: right tile "riverturn" has number 9`, "output size"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Logf("Expected %q to be reported in: %v", expected, err)
+			t.FailNow()
+		}
+	}
+	if len(validationErr.Problems) != 5 {
+		t.Logf("Expected 5 problems, got %d: %v", len(validationErr.Problems), err)
+		t.FailNow()
+	}
+
+	unique := initiateData("castle_data.json")
+	unique.Unique = true
+	if err := unique.Validate(); err == nil || !strings.Contains(err.Error(), `tile "bridge" (index 0) has 1 variants`) {
+		t.Logf("Expected the variant count of unique tiles to be checked, got: %v", err)
+		t.FailNow()
+	}
+
+	// Without rotation, tiles are only used as drawn, which needs a single variant
+	unique.NoRotation = true
+	unique.Neighbors = nil
+	if err := unique.Validate(); err != nil {
+		t.Logf("Expected one variant per tile to be enough without rotation, got: %v", err)
+		t.FailNow()
+	}
+	unique.Tiles = append([]Tile{}, unique.Tiles...)
+	unique.Tiles[0].Variants = []image.Image{unique.Tiles[0].Variants[0], unique.Tiles[0].Variants[0], unique.Tiles[0].Variants[0]}
+	if err := unique.Validate(); err == nil || !strings.Contains(err.Error(), `tile "bridge" (index 0) has 3 variants, symmetry "I" of a unique tileset requires 2, or 1 for the orientations in use`) {
+		t.Logf("Expected the variant count to be checked against the orientations in use, got: %v", err)
+		t.FailNow()
+	}
+}
+
+func TestOverlappingValidation(t *testing.T) {
+	inputImg, err := testutils.LoadImage("internal/input/flowers.png")
+	if err != nil {
+		panic(err)
+	}
+	if _, err := NewOverlappingModelChecked(inputImg, 3, 48, 48, true, true, 2, true); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	_, err = NewOverlappingModelChecked(inputImg, 0, -1, 48, true, true, 9, true)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Problems) != 3 {
+		t.Logf("Expected symmetry, N and size to be reported, got: %v", err)
+		t.FailNow()
+	}
+
+	if _, err := NewOverlappingModelChecked(image.NewRGBA(image.Rect(0, 0, 2, 2)), 3, 48, 48, false, false, 1, false); err == nil {
+		t.Log("Sample smaller than N should be rejected when not periodic.")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,323 @@
+package wfc
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Tileset description as stored in the data.xml files of the reference implementation
+type xmlTiledData struct {
+	Size      string        `xml:"size,attr"`
+	Unique    string        `xml:"unique,attr"`
+	Tiles     []xmlTile     `xml:"tiles>tile"`
+	Neighbors []xmlNeighbor `xml:"neighbors>neighbor"`
+	Subsets   []xmlSubset   `xml:"subsets>subset"`
+}
+
+type xmlTile struct {
+	Name     string `xml:"name,attr"`
+	Symmetry string `xml:"symmetry,attr"`
+	Weight   string `xml:"weight,attr"`
+}
+
+type xmlNeighbor struct {
+	Left  string `xml:"left,attr"`  // Tile name, optionally followed by a space and a variation number
+	Right string `xml:"right,attr"` // Tile name, optionally followed by a space and a variation number
+}
+
+type xmlSubset struct {
+	Name  string    `xml:"name,attr"`
+	Tiles []xmlTile `xml:"tile"`
+}
+
+// List of samples as stored in the samples.xml file of the reference implementation
+type xmlSamples struct {
+	Elements []xmlSample `xml:",any"`
+}
+
+type xmlSample struct {
+	XMLName       xml.Name
+	Name          string `xml:"name,attr"`
+	N             string `xml:"N,attr"`
+	Size          string `xml:"size,attr"`
+	Width         string `xml:"width,attr"`
+	Height        string `xml:"height,attr"`
+	PeriodicInput string `xml:"periodicInput,attr"`
+	Periodic      string `xml:"periodic,attr"`
+	Symmetry      string `xml:"symmetry,attr"`
+	Ground        string `xml:"ground,attr"`
+	Subset        string `xml:"subset,attr"`
+}
+
+// Configuration of an overlapping model, as listed in samples.xml
+type OverlappingSample struct {
+	Name           string // Name of the sample
+	Image          string // Path of the sample image within the file system the samples were loaded from
+	N              int    // Default to 2
+	Width, Height  int    // Default to 48
+	PeriodicInput  bool   // Default to true
+	PeriodicOutput bool   // Default to false
+	Symmetry       int    // Default to 8
+	Ground         bool   // Default to false
+}
+
+// Configuration of a simple tiled model, as listed in samples.xml
+type SimpleTiledSample struct {
+	Name          string // Name of the sample
+	Data          string // Path of the data.xml file within the file system the samples were loaded from
+	Subset        string // Name of the subset of tiles to use, empty for all tiles
+	Width, Height int    // Default to 10
+	Periodic      bool   // Default to false
+}
+
+// Samples listed in samples.xml
+type XMLSamples struct {
+	Overlapping []OverlappingSample
+	SimpleTiled []SimpleTiledSample
+}
+
+/**
+ * LoadXMLSamples
+ * Read a samples.xml file in the format of the reference implementation.
+ * @param {fs.FS} fsys File system holding the samples file
+ * @param {string} name Path of the samples file within fsys
+ * @param {string} dir Directory within fsys holding "<name>.png" sample images and "<name>/data.xml" tilesets
+ * @return XMLSamples The configurations of every overlapping and simple tiled sample
+ * @return error Non-nil if the file cannot be read or holds invalid attributes
+ */
+func LoadXMLSamples(fsys fs.FS, name, dir string) (XMLSamples, error) {
+	raw, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return XMLSamples{}, fmt.Errorf("wfc: reading samples: %w", err)
+	}
+
+	var rawSamples xmlSamples
+	if err := xml.Unmarshal(raw, &rawSamples); err != nil {
+		return XMLSamples{}, fmt.Errorf("wfc: parsing samples %s: %w", name, err)
+	}
+
+	result := XMLSamples{}
+	for i, rs := range rawSamples.Elements {
+		attrs := xmlAttributes{}
+		switch rs.XMLName.Local {
+		case "overlapping":
+			sample := OverlappingSample{Name: rs.Name, Image: path.Join(dir, rs.Name+".png")}
+			sample.N = attrs.parseInt("N", rs.N, 2)
+			size := attrs.parseInt("size", rs.Size, 48)
+			sample.Width = attrs.parseInt("width", rs.Width, size)
+			sample.Height = attrs.parseInt("height", rs.Height, size)
+			sample.PeriodicInput = attrs.parseBool("periodicInput", rs.PeriodicInput, true)
+			sample.PeriodicOutput = attrs.parseBool("periodic", rs.Periodic, false)
+			sample.Symmetry = attrs.parseInt("symmetry", rs.Symmetry, 8)
+			// Older versions store the ground as a pattern offset, where anything but 0 enables it
+			if ground, err := strconv.Atoi(rs.Ground); err == nil {
+				sample.Ground = ground != 0
+			} else {
+				sample.Ground = attrs.parseBool("ground", rs.Ground, false)
+			}
+			result.Overlapping = append(result.Overlapping, sample)
+		case "simpletiled":
+			sample := SimpleTiledSample{Name: rs.Name, Data: path.Join(dir, rs.Name, "data.xml"), Subset: rs.Subset}
+			size := attrs.parseInt("size", rs.Size, 10)
+			sample.Width = attrs.parseInt("width", rs.Width, size)
+			sample.Height = attrs.parseInt("height", rs.Height, size)
+			sample.Periodic = attrs.parseBool("periodic", rs.Periodic, false)
+			result.SimpleTiled = append(result.SimpleTiled, sample)
+		default:
+			continue
+		}
+		if err := attrs.err(); err != nil {
+			return XMLSamples{}, fmt.Errorf("wfc: sample %q (index %d): %w", rs.Name, i, err)
+		}
+	}
+
+	return result, nil
+}
+
+/**
+ * Construct the model described by the sample
+ * @param {fs.FS} fsys File system the samples were loaded from
+ * @return error Non-nil if the image cannot be read, or a *ValidationError if the sample is invalid
+ */
+func (sample OverlappingSample) NewModel(fsys fs.FS) (*OverlappingModel, error) {
+	img, err := loadImage(fsys, sample.Image)
+	if err != nil {
+		return nil, fmt.Errorf("wfc: sample %q: %w", sample.Name, err)
+	}
+	return NewOverlappingModelChecked(img, sample.N, sample.Width, sample.Height, sample.PeriodicInput, sample.PeriodicOutput, sample.Symmetry, sample.Ground)
+}
+
+/**
+ * Construct the model described by the sample
+ * @param {fs.FS} fsys File system the samples were loaded from
+ * @return error Non-nil if the tileset cannot be read, or a *ValidationError if the sample or its tileset is invalid
+ */
+func (sample SimpleTiledSample) NewModel(fsys fs.FS) (*SimpleTiledModel, error) {
+	data, err := LoadXMLTileset(fsys, sample.Data)
+	if err != nil {
+		return nil, err
+	}
+	if sample.Subset != "" {
+		return NewSimpleTiledModelSubset(data, sample.Subset, sample.Width, sample.Height, sample.Periodic)
+	}
+	return NewSimpleTiledModelChecked(data, sample.Width, sample.Height, sample.Periodic)
+}
+
+/**
+ * LoadXMLTileset
+ * Read a data.xml tileset in the format of the reference implementation, along with the images it refers to.
+ * Images are looked up as "<name>.png" in the directory of the data file. When the tileset is unique,
+ * every image "<name> 0.png" through "<name> k.png" is loaded instead.
+ * @param {fs.FS} fsys File system holding the data file and the images
+ * @param {string} name Path of the data file within fsys
+ * @return SimpleTiledData The parsed tiles, constraints and subsets
+ * @return error Non-nil if the data file or one of the images cannot be read
+ */
+func LoadXMLTileset(fsys fs.FS, name string) (SimpleTiledData, error) {
+	raw, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return SimpleTiledData{}, fmt.Errorf("wfc: reading tileset: %w", err)
+	}
+
+	var rawData xmlTiledData
+	if err := xml.Unmarshal(raw, &rawData); err != nil {
+		return SimpleTiledData{}, fmt.Errorf("wfc: parsing tileset %s: %w", name, err)
+	}
+
+	attrs := xmlAttributes{}
+	unique := attrs.parseBool("unique", rawData.Unique, false)
+	tileSize := attrs.parseInt("size", rawData.Size, 0)
+	if err := attrs.err(); err != nil {
+		return SimpleTiledData{}, fmt.Errorf("wfc: tileset %s: %w", name, err)
+	}
+
+	dir := path.Dir(name)
+	tiles := make([]Tile, 0, len(rawData.Tiles))
+	for i, rt := range rawData.Tiles {
+		imgs := make([]image.Image, 0)
+		if unique {
+			for k := 0; ; k++ {
+				img, err := loadImage(fsys, path.Join(dir, rt.Name+" "+strconv.Itoa(k)+".png"))
+				if errors.Is(err, fs.ErrNotExist) {
+					break
+				} else if err != nil {
+					return SimpleTiledData{}, fmt.Errorf("wfc: tile %q (index %d): %w", rt.Name, i, err)
+				}
+				imgs = append(imgs, img)
+			}
+			if len(imgs) == 0 {
+				return SimpleTiledData{}, fmt.Errorf("wfc: tile %q (index %d): missing image %s", rt.Name, i, path.Join(dir, rt.Name+" 0.png"))
+			}
+		} else {
+			img, err := loadImage(fsys, path.Join(dir, rt.Name+".png"))
+			if err != nil {
+				return SimpleTiledData{}, fmt.Errorf("wfc: tile %q (index %d): %w", rt.Name, i, err)
+			}
+			imgs = append(imgs, img)
+		}
+		if tileSize == 0 {
+			tileSize = imgs[0].Bounds().Dx()
+		}
+		symmetry := rt.Symmetry
+		if symmetry == "" {
+			symmetry = "X"
+		}
+		weight := attrs.parseFloat("weight", rt.Weight, 1)
+		if err := attrs.err(); err != nil {
+			return SimpleTiledData{}, fmt.Errorf("wfc: tile %q (index %d): %w", rt.Name, i, err)
+		}
+		tiles = append(tiles, Tile{Name: rt.Name, Symmetry: symmetry, Weight: weight, Variants: imgs})
+	}
+
+	neighbors := make([]Neighbor, 0, len(rawData.Neighbors))
+	for i, rn := range rawData.Neighbors {
+		left, leftNum, err := parseTileReference(rn.Left)
+		if err != nil {
+			return SimpleTiledData{}, fmt.Errorf("wfc: neighbor %d: %w", i, err)
+		}
+		right, rightNum, err := parseTileReference(rn.Right)
+		if err != nil {
+			return SimpleTiledData{}, fmt.Errorf("wfc: neighbor %d: %w", i, err)
+		}
+		neighbors = append(neighbors, Neighbor{Left: left, LeftNum: leftNum, Right: right, RightNum: rightNum})
+	}
+
+	var subsets map[string][]string
+	if len(rawData.Subsets) > 0 {
+		subsets = make(map[string][]string)
+		for _, rs := range rawData.Subsets {
+			names := make([]string, len(rs.Tiles))
+			for i, rt := range rs.Tiles {
+				names[i] = rt.Name
+			}
+			subsets[rs.Name] = names
+		}
+	}
+
+	return SimpleTiledData{Unique: unique, TileSize: tileSize, Tiles: tiles, Neighbors: neighbors, Subsets: subsets}, nil
+}
+
+// Split a "name k" reference into the tile name and its variation number
+func parseTileReference(reference string) (string, int, error) {
+	fields := strings.Fields(reference)
+	switch len(fields) {
+	case 1:
+		return fields[0], 0, nil
+	case 2:
+		num, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return "", 0, fmt.Errorf("invalid variation number in %q", reference)
+		}
+		return fields[0], num, nil
+	default:
+		return "", 0, fmt.Errorf("invalid tile reference %q", reference)
+	}
+}
+
+// Helper to parse optional attributes, remembering the first error encountered
+type xmlAttributes struct {
+	first error
+}
+
+func (attrs *xmlAttributes) parseInt(name, value string, fallback int) int {
+	if value == "" {
+		return fallback
+	}
+	result, err := strconv.Atoi(value)
+	if err != nil && attrs.first == nil {
+		attrs.first = fmt.Errorf("invalid %s %q", name, value)
+	}
+	return result
+}
+
+func (attrs *xmlAttributes) parseFloat(name, value string, fallback float64) float64 {
+	if value == "" {
+		return fallback
+	}
+	result, err := strconv.ParseFloat(value, 64)
+	if err != nil && attrs.first == nil {
+		attrs.first = fmt.Errorf("invalid %s %q", name, value)
+	}
+	return result
+}
+
+func (attrs *xmlAttributes) parseBool(name, value string, fallback bool) bool {
+	if value == "" {
+		return fallback
+	}
+	result, err := strconv.ParseBool(value)
+	if err != nil && attrs.first == nil {
+		attrs.first = fmt.Errorf("invalid %s %q", name, value)
+	}
+	return result
+}
+
+func (attrs *xmlAttributes) err() error {
+	return attrs.first
+}
@@ -0,0 +1,108 @@
+package wfc
+
+import (
+	"errors"
+	"github.com/shawnridgeway/wfc/internal/testutils"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestXMLTilesetMatchesJSON(t *testing.T) {
+	fromJSON := initiateData("castle_data.json")
+	fromXML, err := LoadXMLTileset(os.DirFS("internal/input"), "castle/data.xml")
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if fromXML.TileSize != fromJSON.TileSize || len(fromXML.Tiles) != len(fromJSON.Tiles) {
+		t.Log("XML tileset does not match the JSON tileset.")
+		t.FailNow()
+	}
+	for i := range fromJSON.Tiles {
+		a, b := fromJSON.Tiles[i], fromXML.Tiles[i]
+		if a.Name != b.Name || a.Symmetry != b.Symmetry || a.Weight != b.Weight || !testutils.CompareImages(a.Variants[0], b.Variants[0]) {
+			t.Logf("Tile %q differs between XML and JSON.", a.Name)
+			t.FailNow()
+		}
+	}
+	if !reflect.DeepEqual(fromJSON.Neighbors, fromXML.Neighbors) {
+		t.Log("XML neighbors do not match the JSON neighbors.")
+		t.FailNow()
+	}
+}
+
+func TestXMLTilesetSubset(t *testing.T) {
+	data, err := LoadXMLTileset(os.DirFS("internal/input"), "castle/data.xml")
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if len(data.Subsets["Dry"]) != 7 {
+		t.Log("Subset was not loaded.")
+		t.FailNow()
+	}
+}
+
+func TestXMLSamples(t *testing.T) {
+	fsys := os.DirFS("internal")
+	samples, err := LoadXMLSamples(fsys, "input/samples.xml", "input")
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if len(samples.Overlapping) != 1 || len(samples.SimpleTiled) != 2 {
+		t.Log("Samples were not all parsed.")
+		t.FailNow()
+	}
+	flowers := samples.Overlapping[0]
+	if flowers.N != 3 || flowers.Symmetry != 2 || !flowers.Ground || !flowers.PeriodicInput || !flowers.PeriodicOutput || flowers.Width != 48 {
+		t.Logf("Overlapping sample parsed incorrectly: %+v", flowers)
+		t.FailNow()
+	}
+	dry := samples.SimpleTiled[1]
+	if dry.Subset != "Dry" || dry.Width != 12 || dry.Height != 12 || !dry.Periodic {
+		t.Logf("Simple tiled sample parsed incorrectly: %+v", dry)
+		t.FailNow()
+	}
+
+	// The castle sample reproduces the snapshot of the JSON tileset
+	model, err := samples.SimpleTiled[0].NewModel(fsys)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	model.SetSeed(42)
+	outputImg, success := model.Generate()
+	if !success {
+		t.Log("Failed to generate image on the first try.")
+		t.FailNow()
+	}
+	snapshotImg, err := testutils.LoadImage("internal/snapshots/castle.png")
+	if err != nil {
+		panic(err)
+	}
+	if !testutils.CompareImages(outputImg, snapshotImg) {
+		t.Log("Output image is not the same as the snapshot image.")
+		t.FailNow()
+	}
+
+	if _, err := flowers.NewModel(fsys); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	// Invalid samples are reported instead of giving a broken model
+	flowers.Width, flowers.Symmetry = 0, 9
+	var validationErr *ValidationError
+	if _, err := flowers.NewModel(fsys); !errors.As(err, &validationErr) || len(validationErr.Problems) != 2 {
+		t.Logf("Expected the width and symmetry of the overlapping sample to be reported, got: %v", err)
+		t.FailNow()
+	}
+	castle := samples.SimpleTiled[0]
+	castle.Width = 0
+	if _, err := castle.NewModel(fsys); !errors.As(err, &validationErr) {
+		t.Logf("Expected the width of the simple tiled sample to be reported, got: %v", err)
+		t.FailNow()
+	}
+}